@@ -1,13 +1,20 @@
 package iox
 
+import (
+	"io"
+	"os"
+)
+
 // ConsoleColor console color
 var ConsoleColor = defineColor()
 
 type color struct {
 	Red     string
+	Green   string
 	Magenta string
 	Yellow  string
 	Blue    string
+	Cyan    string
 	White   string
 	Gray    string
 	Reset   string
@@ -16,11 +23,30 @@ type color struct {
 func defineColor() *color {
 	return &color{
 		Red:     "\x1b[91m",
+		Green:   "\x1b[92m",
 		Magenta: "\x1b[95m",
 		Yellow:  "\x1b[93m",
 		Blue:    "\x1b[94m",
+		Cyan:    "\x1b[96m",
 		White:   "\x1b[97m",
 		Gray:    "\x1b[90m",
 		Reset:   "\x1b[0m",
 	}
 }
+
+// IsTerminal check the writer w is a terminal
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isTerminal(f)
+}
+
+// IsColorTerminal check the writer w is a terminal and the environment variable NO_COLOR is not set
+func IsColorTerminal(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return IsTerminal(w)
+}
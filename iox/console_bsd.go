@@ -0,0 +1,8 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+// +build darwin dragonfly freebsd netbsd openbsd
+
+package iox
+
+import "syscall"
+
+const ioctlGetTermios = syscall.TIOCGETA
@@ -0,0 +1,5 @@
+package iox
+
+import "syscall"
+
+const ioctlGetTermios = syscall.TCGETS
@@ -0,0 +1,12 @@
+package iox
+
+import (
+	"os"
+	"syscall"
+)
+
+// isTerminal check the file f is a console by getting the console mode
+func isTerminal(f *os.File) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(f.Fd()), &mode) == nil
+}
@@ -0,0 +1,218 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// max count of the aggregation groups
+const maxAggregateGroups = 1000
+
+// aggregate group of the identical events
+type aggregateGroup struct {
+	sample *Event    // the first event
+	first  time.Time // the time of the first suppressed event
+	last   time.Time // the time of the last suppressed event
+	count  int       // the count of the suppressed events
+}
+
+// AggregateWriter implements Writer.
+// It wraps a writer (normally a alert writer like SMTPWriter, SlackWriter),
+// groups the identical (level, logger, message prefix) events within a time window.
+// The first event of a group is written to the wrapped writer immediately,
+// the subsequent identical events within the window are suppressed,
+// and a digest event with the count, the first/last timestamps and the sample trace of the suppressed events
+// is written to the wrapped writer when the window is expired.
+type AggregateWriter struct {
+	Writer Writer        // the wrapped writer
+	Window time.Duration // aggregation time window, default: 1 minute
+	Prefix int           // length of the message prefix to group the events, 0: the whole message
+	Logfil Filter        // log filter
+
+	wstat  writerStat // the writer statistics
+	mutex  sync.Mutex
+	groups map[string]*aggregateGroup
+	timer  *time.Timer
+}
+
+// NewAggregateWriter create a aggregate writer wraps the writer 'w'
+func NewAggregateWriter(w Writer, window time.Duration) *AggregateWriter {
+	return &AggregateWriter{Writer: w, Window: window}
+}
+
+// SetWriter create the wrapped writer by the registered writer name
+func (aw *AggregateWriter) SetWriter(name string) error {
+	w := CreateWriter(name)
+	if w == nil {
+		return fmt.Errorf("AggregateWriter - Invalid writer name: %v", name)
+	}
+	aw.Writer = w
+	return nil
+}
+
+// SetWindow set the aggregation time window
+func (aw *AggregateWriter) SetWindow(window string) error {
+	w, err := time.ParseDuration(window)
+	if err != nil {
+		return fmt.Errorf("AggregateWriter - Invalid window: %v", err)
+	}
+	aw.Window = w
+	return nil
+}
+
+// SetFilter set the log filter
+func (aw *AggregateWriter) SetFilter(filter string) error {
+	f, err := ParseLogFilter(filter)
+	if err != nil {
+		return fmt.Errorf("AggregateWriter - %v", err)
+	}
+	aw.Logfil = f
+	return nil
+}
+
+// Unwrap return the wrapped writer
+func (aw *AggregateWriter) Unwrap() Writer {
+	return aw.Writer
+}
+
+// stats return the statistics of the writer
+func (aw *AggregateWriter) stats() *writerStat {
+	return &aw.wstat
+}
+
+// Write write the first event of a group to the wrapped writer, suppress the subsequent identical events.
+func (aw *AggregateWriter) Write(le *Event) {
+	if aw.Writer == nil {
+		return
+	}
+	if reject(&aw.wstat, aw.Logfil, le) {
+		return
+	}
+
+	aw.mutex.Lock()
+	defer aw.mutex.Unlock()
+
+	if aw.Window <= 0 {
+		aw.Window = time.Minute
+	}
+	if aw.groups == nil {
+		aw.groups = make(map[string]*aggregateGroup)
+	}
+
+	key := aw.key(le)
+	if ag, ok := aw.groups[key]; ok {
+		if ag.count == 0 {
+			ag.first = le.When
+		}
+		ag.last = le.When
+		ag.count++
+		return
+	}
+
+	if len(aw.groups) < maxAggregateGroups {
+		// copy the event, because the event will be put back to the pool
+		ce := &Event{}
+		*ce = *le
+		aw.groups[key] = &aggregateGroup{sample: ce}
+
+		if aw.timer == nil {
+			aw.timer = time.AfterFunc(aw.Window, aw.expire)
+		}
+	}
+
+	aw.Writer.Write(le)
+}
+
+// Flush write the digest events of all groups to the wrapped writer, and flush the wrapped writer.
+func (aw *AggregateWriter) Flush() {
+	if aw.Writer == nil {
+		return
+	}
+
+	aw.mutex.Lock()
+	defer aw.mutex.Unlock()
+
+	aw.digest(time.Time{})
+	aw.Writer.Flush()
+}
+
+// Close write the digest events of all groups to the wrapped writer, and close the wrapped writer.
+func (aw *AggregateWriter) Close() {
+	if aw.Writer == nil {
+		return
+	}
+
+	aw.mutex.Lock()
+	defer aw.mutex.Unlock()
+
+	aw.digest(time.Time{})
+	if aw.timer != nil {
+		aw.timer.Stop()
+		aw.timer = nil
+	}
+	aw.Writer.Close()
+}
+
+// key get the group key of the event
+func (aw *AggregateWriter) key(le *Event) string {
+	msg := le.Msg
+	if aw.Prefix > 0 && len(msg) > aw.Prefix {
+		msg = msg[:aw.Prefix]
+	}
+
+	name := ""
+	if le.Logger != nil {
+		name = le.Logger.GetName()
+	}
+	return le.Level.Prefix() + "\x00" + name + "\x00" + msg
+}
+
+// expire write the digest events of the expired groups (called by timer)
+func (aw *AggregateWriter) expire() {
+	aw.mutex.Lock()
+	defer aw.mutex.Unlock()
+
+	aw.timer = nil
+	aw.digest(time.Now().Add(-aw.Window))
+	if len(aw.groups) > 0 {
+		aw.timer = time.AfterFunc(aw.Window, aw.expire)
+	}
+}
+
+// digest write the digest events of the groups which sample event is before the time 'due' (zero: all groups)
+func (aw *AggregateWriter) digest(due time.Time) {
+	for key, ag := range aw.groups {
+		if !due.IsZero() && ag.sample.When.After(due) {
+			continue
+		}
+
+		if ag.count > 0 {
+			le := aw.digestEvent(ag)
+			aw.Writer.Write(le)
+		}
+		delete(aw.groups, key)
+	}
+}
+
+// digestEvent create a digest event of the group
+func (aw *AggregateWriter) digestEvent(ag *aggregateGroup) *Event {
+	le := &Event{}
+	*le = *ag.sample
+
+	le.When = ag.last
+	le.Msg = fmt.Sprintf("%s (repeated %d times from %s to %s)",
+		ag.sample.Msg, ag.count, ag.first.Format(defaultTimeFormat), ag.last.Format(defaultTimeFormat))
+	le.Fields = mergeFields(le.Fields, map[string]interface{}{
+		"repeated": ag.count,
+		"first":    ag.first,
+		"last":     ag.last,
+	})
+	return le
+}
+
+func init() {
+	RegisterWriter("aggregate", func() Writer {
+		return &AggregateWriter{Window: time.Minute}
+	})
+}
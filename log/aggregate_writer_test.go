@@ -0,0 +1,95 @@
+package log
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateWriter(t *testing.T) {
+	bw := &testBatchWriter{}
+
+	log := NewLog()
+	log.SetWriter(NewAggregateWriter(bw, time.Hour))
+	log.Error("disk full")
+	log.Error("disk full")
+	log.Error("disk full")
+	log.Warn("disk full")
+	log.Error("network down")
+	log.Flush()
+
+	if assert.Equal(t, 4, len(bw.msgs)) {
+		assert.Equal(t, []string{"disk full", "disk full", "network down"}, bw.msgs[:3])
+		assert.True(t, strings.HasPrefix(bw.msgs[3], "disk full (repeated 2 times from "), bw.msgs[3])
+	}
+
+	// groups are reset after flush
+	log.Error("disk full")
+	log.Close()
+
+	assert.Equal(t, 5, len(bw.msgs))
+	assert.Equal(t, "disk full", bw.msgs[4])
+	assert.True(t, bw.closed)
+}
+
+func TestAggregateWriterPrefix(t *testing.T) {
+	bw := &testBatchWriter{}
+
+	log := NewLog()
+	log.SetWriter(&AggregateWriter{Writer: bw, Window: time.Hour, Prefix: 7})
+	log.Error("timeout 1")
+	log.Error("timeout 2")
+	log.Error("timeout 3")
+	log.Close()
+
+	if assert.Equal(t, 2, len(bw.msgs)) {
+		assert.Equal(t, "timeout 1", bw.msgs[0])
+		assert.True(t, strings.HasPrefix(bw.msgs[1], "timeout 1 (repeated 2 times from "), bw.msgs[1])
+	}
+}
+
+func TestAggregateWriterWindow(t *testing.T) {
+	bw := &testBatchWriter{}
+
+	log := NewLog()
+	log.SetWriter(NewAggregateWriter(bw, time.Millisecond*100))
+	log.Error("disk full")
+	log.Error("disk full")
+
+	time.Sleep(time.Millisecond * 500)
+
+	bw.mu.Lock()
+	msgs := append([]string{}, bw.msgs...)
+	bw.mu.Unlock()
+
+	if assert.Equal(t, 2, len(msgs)) {
+		assert.True(t, strings.HasPrefix(msgs[1], "disk full (repeated 1 times from "), msgs[1])
+	}
+
+	log.Error("disk full")
+	log.Close()
+	assert.Equal(t, 3, len(bw.msgs))
+}
+
+func TestAggregateWriterConfig(t *testing.T) {
+	aw := CreateWriter("aggregate").(*AggregateWriter)
+	err := ConfigWriter(aw, map[string]interface{}{
+		"writer":        "stdout",
+		"window":        "5m",
+		"prefix":        20,
+		"filter":        "level:error",
+		"writer.format": "%l - %m%n",
+		"writer.filter": "level:warn",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, time.Minute*5, aw.Window)
+	assert.Equal(t, 20, aw.Prefix)
+	assert.NotNil(t, aw.Logfil)
+	if sw, ok := aw.Writer.(*StreamWriter); assert.True(t, ok) {
+		assert.NotNil(t, sw.Logfmt)
+		assert.Equal(t, LevelWarn, sw.Logfil.(*LevelFilter).Level)
+	}
+}
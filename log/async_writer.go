@@ -0,0 +1,211 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AsyncWriter implements Writer.
+// It wraps a writer, buffers the events and writes them to the wrapped writer in a goroutine.
+// If the wrapped writer implements BatchWriter, the buffered events are written in batch
+// when the count of the buffered events reaches BatchSize or at every FlushInterval.
+type AsyncWriter struct {
+	dropped uint64 // dropped event count (64-bit aligned for atomic)
+
+	Writer        Writer        // the wrapped writer
+	BufferSize    int           // event buffer size, default: 1000
+	BatchSize     int           // batch size, default: 1 (no batch)
+	FlushInterval time.Duration // flush interval of the batch
+	Overflow      Overflow      // overflow policy when the event buffer is full
+	OverflowLevel Level         // overflow level of the OverflowDropBelow policy
+
+	evtChan chan *Event
+	sigChan chan string
+	waitg   sync.WaitGroup
+	batch   []*Event
+}
+
+// NewAsyncWriter create a asynchronous writer wraps the writer 'w'
+func NewAsyncWriter(w Writer, bufferSize int) *AsyncWriter {
+	return &AsyncWriter{Writer: w, BufferSize: bufferSize}
+}
+
+// SetWriter create the wrapped writer by the registered writer name
+func (aw *AsyncWriter) SetWriter(name string) error {
+	w := CreateWriter(name)
+	if w == nil {
+		return fmt.Errorf("AsyncWriter - Invalid writer name: %v", name)
+	}
+	aw.Writer = w
+	return nil
+}
+
+// SetFlushInterval set the flush interval
+func (aw *AsyncWriter) SetFlushInterval(interval string) error {
+	fi, err := time.ParseDuration(interval)
+	if err != nil {
+		return fmt.Errorf("AsyncWriter - Invalid flushInterval: %v", err)
+	}
+	aw.FlushInterval = fi
+	return nil
+}
+
+// SetOverflow set the overflow policy
+func (aw *AsyncWriter) SetOverflow(overflow string) error {
+	ov, err := ParseOverflow(overflow)
+	if err != nil {
+		return fmt.Errorf("AsyncWriter - %v", err)
+	}
+	aw.Overflow = ov
+	return nil
+}
+
+// SetOverflowLevel set the overflow level
+func (aw *AsyncWriter) SetOverflowLevel(lvl string) {
+	aw.OverflowLevel = ParseLevel(lvl)
+}
+
+// Unwrap return the wrapped writer
+func (aw *AsyncWriter) Unwrap() Writer {
+	return aw.Writer
+}
+
+// GetDroppedCount return the count of the events dropped by the overflow policy
+func (aw *AsyncWriter) GetDroppedCount() uint64 {
+	return atomic.LoadUint64(&aw.dropped)
+}
+
+// Write copy the log event and put it to the event buffer.
+func (aw *AsyncWriter) Write(le *Event) {
+	if aw.Writer == nil {
+		return
+	}
+
+	aw.start()
+
+	// copy the event, because the event will be put back to the pool
+	ce := &Event{}
+	*ce = *le
+
+	enqueueEvent(aw.evtChan, ce, aw.Overflow, aw.OverflowLevel, aw.drop)
+}
+
+// Flush write all the buffered events to the wrapped writer and flush it.
+func (aw *AsyncWriter) Flush() {
+	if aw.evtChan != nil {
+		aw.execSignal("flush")
+	}
+}
+
+// Close write all the buffered events to the wrapped writer, stop the goroutine and close the wrapped writer.
+func (aw *AsyncWriter) Close() {
+	if aw.evtChan != nil {
+		aw.execSignal("close")
+		close(aw.evtChan)
+		close(aw.sigChan)
+		aw.evtChan = nil
+		aw.sigChan = nil
+		return
+	}
+
+	if aw.Writer != nil {
+		aw.Writer.Close()
+	}
+}
+
+func (aw *AsyncWriter) drop(le *Event) {
+	atomic.AddUint64(&aw.dropped, 1)
+}
+
+// start start the goroutine
+func (aw *AsyncWriter) start() {
+	if aw.evtChan != nil {
+		return
+	}
+
+	if aw.BufferSize < 1 {
+		aw.BufferSize = 1000
+	}
+	if aw.BatchSize < 1 {
+		aw.BatchSize = 1
+	}
+
+	aw.evtChan = make(chan *Event, aw.BufferSize)
+	aw.sigChan = make(chan string, 1)
+	go aw.run(aw.evtChan, aw.sigChan)
+}
+
+// execSignal send a signal and wait for done
+func (aw *AsyncWriter) execSignal(sig string) {
+	aw.waitg.Add(1)
+	aw.sigChan <- sig
+	aw.waitg.Wait()
+}
+
+// run the goroutine
+func (aw *AsyncWriter) run(evtChan chan *Event, sigChan chan string) {
+	var tick <-chan time.Time
+	if aw.BatchSize > 1 && aw.FlushInterval > 0 {
+		ticker := time.NewTicker(aw.FlushInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case le := <-evtChan:
+			aw.append(le)
+		case <-tick:
+			aw.writeBatch()
+		case sg := <-sigChan:
+			for len(evtChan) > 0 {
+				aw.append(<-evtChan)
+			}
+			aw.writeBatch()
+			aw.Writer.Flush()
+
+			if sg == "close" {
+				aw.Writer.Close()
+				aw.waitg.Done()
+				return
+			}
+			aw.waitg.Done()
+		}
+	}
+}
+
+// append append the event to the batch, write the batch if the batch is full
+func (aw *AsyncWriter) append(le *Event) {
+	aw.batch = append(aw.batch, le)
+	if len(aw.batch) >= aw.BatchSize {
+		aw.writeBatch()
+	}
+}
+
+// writeBatch write the batch events to the wrapped writer
+func (aw *AsyncWriter) writeBatch() {
+	if len(aw.batch) == 0 {
+		return
+	}
+
+	if bw, ok := aw.Writer.(BatchWriter); ok && len(aw.batch) > 1 {
+		bw.WriteBatch(aw.batch)
+	} else {
+		for _, le := range aw.batch {
+			aw.Writer.Write(le)
+		}
+	}
+
+	for i := range aw.batch {
+		aw.batch[i] = nil
+	}
+	aw.batch = aw.batch[:0]
+}
+
+func init() {
+	RegisterWriter("async", func() Writer {
+		return &AsyncWriter{}
+	})
+}
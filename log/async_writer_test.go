@@ -0,0 +1,166 @@
+package log
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testBatchWriter a writer records the events and the batches
+type testBatchWriter struct {
+	mu      sync.Mutex
+	msgs    []string
+	batches []int
+	flushed int
+	closed  bool
+}
+
+func (bw *testBatchWriter) Write(le *Event) {
+	bw.mu.Lock()
+	bw.msgs = append(bw.msgs, le.Msg)
+	bw.batches = append(bw.batches, 1)
+	bw.mu.Unlock()
+}
+
+func (bw *testBatchWriter) WriteBatch(les []*Event) {
+	bw.mu.Lock()
+	for _, le := range les {
+		bw.msgs = append(bw.msgs, le.Msg)
+	}
+	bw.batches = append(bw.batches, len(les))
+	bw.mu.Unlock()
+}
+
+func (bw *testBatchWriter) Flush() {
+	bw.flushed++
+}
+
+func (bw *testBatchWriter) Close() {
+	bw.closed = true
+}
+
+func TestAsyncWriter(t *testing.T) {
+	bw := &testBatchWriter{}
+
+	log := NewLog()
+	log.SetWriter(NewAsyncWriter(bw, 10))
+	log.Info("1")
+	log.Info("2")
+	log.Flush()
+	log.Info("3")
+	log.Close()
+
+	assert.Equal(t, []string{"1", "2", "3"}, bw.msgs)
+	assert.Equal(t, []int{1, 1, 1}, bw.batches)
+	assert.Equal(t, 3, bw.flushed)
+	assert.True(t, bw.closed)
+}
+
+func TestAsyncWriterBatch(t *testing.T) {
+	bw := &testBatchWriter{}
+
+	log := NewLog()
+	log.SetWriter(&AsyncWriter{Writer: bw, BatchSize: 3})
+	for _, s := range []string{"1", "2", "3", "4", "5"} {
+		log.Info(s)
+	}
+	log.Close()
+
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, bw.msgs)
+	assert.Equal(t, []int{3, 2}, bw.batches)
+}
+
+func TestAsyncWriterFlushInterval(t *testing.T) {
+	bw := &testBatchWriter{}
+
+	log := NewLog()
+	log.SetWriter(&AsyncWriter{Writer: bw, BatchSize: 100, FlushInterval: time.Millisecond * 10})
+	log.Info("1")
+	log.Info("2")
+
+	time.Sleep(time.Millisecond * 100)
+	bw.mu.Lock()
+	assert.Equal(t, []int{2}, bw.batches)
+	bw.mu.Unlock()
+
+	log.Close()
+}
+
+func TestAsyncWriterConfig(t *testing.T) {
+	w := CreateWriter("async")
+	err := ConfigWriter(w, map[string]interface{}{
+		"_":                  "async",
+		"writer.webhook":     "http://localhost/logs",
+		"writer.contentType": "application/json",
+		"writer.filter":      "level:error",
+		"writer":             "webhook",
+		"bufferSize":         "100",
+		"batchSize":          "10",
+		"flushInterval":      "5s",
+		"overflow":           "drop-below",
+		"overflowLevel":      "warn",
+	})
+	assert.Nil(t, err)
+
+	aw := w.(*AsyncWriter)
+	assert.Equal(t, 100, aw.BufferSize)
+	assert.Equal(t, 10, aw.BatchSize)
+	assert.Equal(t, time.Second*5, aw.FlushInterval)
+	assert.Equal(t, OverflowDropBelow, aw.Overflow)
+	assert.Equal(t, LevelWarn, aw.OverflowLevel)
+
+	ww := aw.Writer.(*WebhookWriter)
+	assert.Equal(t, "http://localhost/logs", ww.Webhook)
+	assert.Equal(t, "application/json", ww.ContentType)
+	assert.Equal(t, LevelError, ww.Logfil.(*LevelFilter).Level)
+
+	assert.NotNil(t, ConfigWriter(CreateWriter("async"), map[string]interface{}{"writer": "unknown"}))
+	assert.NotNil(t, ConfigWriter(CreateWriter("async"), map[string]interface{}{"writer": "webhook", "overflow": "unknown"}))
+	assert.NotNil(t, ConfigWriter(CreateWriter("async"), map[string]interface{}{"writer": "webhook", "webhook": "http://localhost/logs"}))
+	assert.NotNil(t, ConfigWriter(CreateWriter("async"), map[string]interface{}{"writer.webhook": "http://localhost/logs"}))
+}
+
+func TestAsyncWriterWebhookBatch(t *testing.T) {
+	var mu sync.Mutex
+	var bodies [][]map[string]interface{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bs, _ := ioutil.ReadAll(r.Body)
+
+		var a []map[string]interface{}
+		if err := json.Unmarshal(bs, &a); err != nil {
+			t.Errorf("Invalid json array %q: %v", string(bs), err)
+		}
+
+		mu.Lock()
+		bodies = append(bodies, a)
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	log := NewLog()
+	log.SetWriter(&AsyncWriter{
+		Writer: &WebhookWriter{
+			Webhook: ts.URL,
+			Logfmt:  NewJSONFormatter(`{"level": %l, "msg": %m}%n`),
+			Logfil:  NewLevelFilter(LevelWarn),
+		},
+		BatchSize: 10,
+	})
+	log.Error("e1")
+	log.Info("i1")
+	log.Warn("w1")
+	log.Close()
+
+	assert.Equal(t, 1, len(bodies))
+	assert.Equal(t, []map[string]interface{}{
+		{"level": "ERROR", "msg": "e1"},
+		{"level": "WARN", "msg": "w1"},
+	}, bodies[0])
+}
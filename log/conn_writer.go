@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"net"
-	"os"
 	"time"
 )
 
@@ -18,8 +17,9 @@ type ConnWriter struct {
 	Logfmt  Formatter // log formatter
 	Logfil  Filter    // log filter
 
-	conn io.WriteCloser
-	bb   bytes.Buffer
+	wstat writerStat // the writer statistics
+	conn  io.WriteCloser
+	bb    bytes.Buffer
 }
 
 // SetFormat set the log formatter
@@ -28,8 +28,13 @@ func (cw *ConnWriter) SetFormat(format string) {
 }
 
 // SetFilter set the log filter
-func (cw *ConnWriter) SetFilter(filter string) {
-	cw.Logfil = NewLogFilter(filter)
+func (cw *ConnWriter) SetFilter(filter string) error {
+	f, err := ParseLogFilter(filter)
+	if err != nil {
+		return fmt.Errorf("ConnWriter - %v", err)
+	}
+	cw.Logfil = f
+	return nil
 }
 
 // SetTimeout set timeout
@@ -42,9 +47,14 @@ func (cw *ConnWriter) SetTimeout(timeout string) error {
 	return nil
 }
 
+// stats return the statistics of the writer
+func (cw *ConnWriter) stats() *writerStat {
+	return &cw.wstat
+}
+
 // Write write logger message to connection.
 func (cw *ConnWriter) Write(le *Event) {
-	if cw.Logfil != nil && cw.Logfil.Reject(le) {
+	if reject(&cw.wstat, cw.Logfil, le) {
 		return
 	}
 
@@ -76,7 +86,7 @@ func (cw *ConnWriter) Write(le *Event) {
 		}
 		_, err := cw.conn.Write(cw.bb.Bytes())
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "ConnWriter(%q) - Write(%s): %v\n", cw.Addr, cw.bb.Bytes(), err)
+			writerErrorf(cw, "ConnWriter(%q) - Write(%s): %v\n", cw.Addr, cw.bb.Bytes(), err)
 			cw.Close()
 		}
 	}
@@ -91,7 +101,7 @@ func (cw *ConnWriter) Close() {
 	if cw.conn != nil {
 		err := cw.conn.Close()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "ConnWriter(%q) - Close(): %v\n", cw.Addr, err)
+			writerErrorf(cw, "ConnWriter(%q) - Close(): %v\n", cw.Addr, err)
 		}
 		cw.conn = nil
 	}
@@ -108,7 +118,7 @@ func (cw *ConnWriter) dial() {
 
 	conn, err := net.DialTimeout(cw.Net, cw.Addr, cw.Timeout)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "ConnWriter(%q) - Dial(%q): %v\n", cw.Addr, cw.Net, err)
+		writerErrorf(cw, "ConnWriter(%q) - Dial(%q): %v\n", cw.Addr, cw.Net, err)
 		return
 	}
 
@@ -0,0 +1,72 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// ECSVersion the Elastic Common Schema version of the ECSFormatter
+const ECSVersion = "1.6.0"
+
+// ECSFormatter Elastic Common Schema json formatter
+// The logger properties and the structured fields are written as the top level fields.
+// The caller info are written as "log.origin.file.name", "log.origin.file.line", "log.origin.function".
+// The logged error is written as "error.type", "error.message" and "error.stack_trace" (the stack trace where the error was created).
+// The stack trace of the event without error is written as "log.origin.stack_trace".
+type ECSFormatter struct {
+}
+
+// NewECSFormatter create a ECS Formatter instance
+func NewECSFormatter() *ECSFormatter {
+	return &ECSFormatter{}
+}
+
+// Write format the log event as a ECS json to the buffer 'bb'
+func (ef *ECSFormatter) Write(bb *bytes.Buffer, le *Event) {
+	m := map[string]interface{}{}
+
+	name := ""
+	if le.Logger != nil {
+		name = le.Logger.GetName()
+		for k, v := range le.Logger.GetProps() {
+			m[k] = v
+		}
+	}
+	for k, v := range le.Fields {
+		m[k] = v
+	}
+
+	m["@timestamp"] = le.When.Format("2006-01-02T15:04:05.000Z07:00")
+	m["log.level"] = strings.ToLower(le.Level.String())
+	m["log.logger"] = name
+	m["message"] = le.Msg
+	m["ecs.version"] = ECSVersion
+	if le.File != "" {
+		m["log.origin.file.name"] = le.File
+		m["log.origin.file.line"] = le.Line
+		m["log.origin.function"] = le.Func
+	}
+	if ei := le.Error; ei != nil {
+		m["error.type"] = ei.Type
+		m["error.message"] = ei.Message
+		if ei.Stack != "" {
+			m["error.stack_trace"] = ei.Stack
+		} else if le.Trace != "" {
+			m["error.stack_trace"] = le.Trace
+		}
+	} else if le.Trace != "" {
+		m["log.origin.stack_trace"] = le.Trace
+	}
+
+	b, _ := json.Marshal(m)
+	bb.Write(b)
+	bb.WriteString(eol)
+}
+
+// Format format the log event to a ECS json string
+func (ef *ECSFormatter) Format(le *Event) string {
+	bb := &bytes.Buffer{}
+	ef.Write(bb, le)
+	return bb.String()
+}
@@ -0,0 +1,38 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestECSFormat(t *testing.T) {
+	ef := NewLogFormatter("ecs:")
+	lg := NewLog().GetLogger("app")
+	lg.SetProp("service.name", "svc")
+	le := newEvent(lg.With("user.id", "u1"), LevelWarn, "msg")
+	le.When = time.Date(2020, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	le.File = ""
+	assert.Equal(t, `{"@timestamp":"2020-01-02T03:04:05.006Z","ecs.version":"1.6.0","log.level":"warn","log.logger":"app","message":"msg","service.name":"svc","user.id":"u1"}`+eol, ef.Format(le))
+}
+
+func TestECSFormatCaller(t *testing.T) {
+	ef := NewECSFormatter()
+	le := newEvent(NewLog().GetLogger(""), LevelError, "msg")
+	le.When = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	le.File = "a.go"
+	le.Line = 10
+	le.Func = "main.main"
+	le.Trace = "trace"
+	assert.Equal(t, `{"@timestamp":"2020-01-02T03:04:05.000Z","ecs.version":"1.6.0","log.level":"error","log.logger":"","log.origin.file.line":10,"log.origin.file.name":"a.go","log.origin.function":"main.main","log.origin.stack_trace":"trace","message":"msg"}`+eol, ef.Format(le))
+
+	le.Error = &ErrorInfo{Type: "*errors.errorString", Message: "err"}
+	assert.Equal(t, `{"@timestamp":"2020-01-02T03:04:05.000Z","ecs.version":"1.6.0","error.message":"err","error.stack_trace":"trace","error.type":"*errors.errorString","log.level":"error","log.logger":"","log.origin.file.line":10,"log.origin.file.name":"a.go","log.origin.function":"main.main","message":"msg"}`+eol, ef.Format(le))
+}
+
+func TestECSFormatNoLogger(t *testing.T) {
+	ef := NewECSFormatter()
+	le := &Event{Level: LevelInfo, Msg: "msg", When: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+	assert.Equal(t, `{"@timestamp":"2020-01-02T03:04:05.000Z","ecs.version":"1.6.0","log.level":"info","log.logger":"","message":"msg"}`+eol, ef.Format(le))
+}
@@ -0,0 +1,45 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package log
+
+import (
+	"os"
+	"runtime"
+	"time"
+)
+
+// lockFile create the lock file 'path' exclusively, and keep it opened until unlockFile().
+// On Windows, the opened lock file can not be removed by other process,
+// so the existing lock file is removed if it's not opened (the owner process is crashed).
+// On other systems, the existing lock file is removed only if it's stale (not modified in fileLockTimeout).
+func lockFile(path string, perm os.FileMode) (*os.File, error) {
+	for i := 0; i < 2; i++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+		if err == nil {
+			return f, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if runtime.GOOS != "windows" {
+			if fi, err := os.Stat(path); err != nil || time.Since(fi.ModTime()) <= fileLockTimeout {
+				break
+			}
+		}
+		if os.Remove(path) != nil {
+			break
+		}
+	}
+	return nil, errFileLocked
+}
+
+// unlockFile close and remove the lock file
+func unlockFile(f *os.File) error {
+	f.Close()
+	if err := os.Remove(f.Name()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package log
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile acquire the exclusive flock of the lock file 'path' (create if not exists).
+// The flock is released by the OS if the process is crashed, so the lock file is never stale.
+// The lock file is removed by unlockFile(), so the locked file is checked that
+// it's not removed by other process before it's locked.
+func lockFile(path string, perm os.FileMode) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, errFileLocked
+		}
+		return nil, err
+	}
+
+	fi, err := os.Stat(path)
+	if err == nil {
+		var ofi os.FileInfo
+		if ofi, err = f.Stat(); err == nil && os.SameFile(fi, ofi) {
+			return f, nil
+		}
+	}
+
+	// removed (and maybe recreated) by other process after it is opened
+	f.Close()
+	if err == nil || os.IsNotExist(err) {
+		return nil, errFileLocked
+	}
+	return nil, err
+}
+
+// unlockFile remove the lock file and release the flock.
+// The lock file is removed before it's unlocked, so the removed file is always owned by this process.
+func unlockFile(f *os.File) error {
+	err := os.Remove(f.Name())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -1,31 +1,58 @@
 package log
 
 import (
+	"bufio"
 	"bytes"
-	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// the timeout of the stale lock file and the temporary compressing file
+const fileLockTimeout = time.Minute
+
+// errFileLocked the lock file is locked by other process
+var errFileLocked = errors.New("file is locked")
+
 // FileWriter implements Writer.
 // It writes messages and rotate by file size limit, daily, hourly.
+// The rotated files are deleted by the retention policies (MaxTotalSize, MaxAge)
+// at startup and every rotation.
+// In buffered mode (BufferSize > 0), the messages are written to the file when the buffer is full,
+// at every FlushInterval, on Flush/Close, or immediately if the level <= SyncLevel.
+// In Shared mode, the rotation is decided by the actual size of the log file on disk,
+// and is serialized by a lock file (Path + ".lock"), so multiple processes can write the same log file.
+// The records are written as a whole (a record is never split into 2 writes) to the file opened with O_APPEND.
+// The rotated files are compressed by a background worker one by one,
+// the half-compressed files left by a crash are recovered at startup.
 type FileWriter struct {
-	Path      string    // Log file path name
-	DirPerm   uint32    // Log dir permission
-	FilePerm  uint32    // Log file permission
-	MaxSplit  int       // Max split files
-	MaxSize   int64     // Rotate at size
-	MaxDays   int       // Max daily files
-	MaxHours  int       // Max hourly files
-	Gzip      bool      // Compress rotated log files
-	SyncLevel Level     // Call File.Sync() if level <= SyncLevel
-	Logfmt    Formatter // log formatter
-	Logfil    Filter    // log filter
-
+	Path          string        // Log file path name
+	DirPerm       uint32        // Log dir permission
+	FilePerm      uint32        // Log file permission
+	MaxSplit      int           // Max split files
+	MaxSize       int64         // Rotate at size
+	MaxDays       int           // Max daily files
+	MaxHours      int           // Max hourly files
+	MaxTotalSize  int64         // Max total size of the rotated files and the log file (MaxSize is reserved for the log file)
+	MaxAge        time.Duration // Max age of the rotated files
+	Gzip          bool          // Compress rotated log files by gzip (same as Compress = "gzip")
+	Compress      string        // Compressor name of the rotated log files: gzip, zlib, lzw or other registered compressor
+	CompressDelay time.Duration // Compress the rotated log file after the delay (keep the recent rotated file uncompressed for tailing)
+	Shared        bool          // Multi-process safe mode (the log file is shared by multiple processes)
+	BufferSize    int           // Write buffer size, 0: no buffer
+	FlushInterval time.Duration // Flush the write buffer at every interval (buffered mode)
+	SyncLevel     Level         // Flush the write buffer and call File.Sync() if level <= SyncLevel
+	Logfmt        Formatter     // log formatter
+	Logfil        Filter        // log filter
+
+	wstat    writerStat // the writer statistics
 	dir      string
 	prefix   string
 	suffix   string
@@ -36,6 +63,29 @@ type FileWriter struct {
 	openDay  int
 	openHour int
 	bb       bytes.Buffer
+	bw       *bufio.Writer
+	mutex    sync.Mutex
+	lockf    *os.File  // the acquired lock file (Shared mode)
+	lockw    time.Time // the time of the first failed lock attempt (Shared mode)
+	done     chan struct{}
+	tasks    chan fileTask
+	tdone    chan struct{}
+}
+
+// file task kinds of the background file worker
+const (
+	taskCompress = iota // compress the rotated file
+	taskRecover         // recover the half-compressed files
+	taskDelete          // delete the outdated and exceeded rotated files
+)
+
+// fileTask the task of the background file worker.
+// The tasks are executed one by one, so a rotated file is never deleted while it is being compressed.
+type fileTask struct {
+	kind int       // taskCompress, taskRecover, taskDelete
+	path string    // the rotated file path (taskCompress)
+	due  time.Time // execute after the due time (taskRecover: the startup time)
+	size int64     // the reserved size of the current log file (taskDelete)
 }
 
 // SetSyncLevel set the sync level
@@ -43,19 +93,77 @@ func (fw *FileWriter) SetSyncLevel(lvl string) {
 	fw.SyncLevel = ParseLevel(lvl)
 }
 
+// SetMaxAge set the max age of the rotated files (a duration like "72h", or days like "7d")
+func (fw *FileWriter) SetMaxAge(age string) error {
+	if strings.HasSuffix(age, "d") {
+		n, err := strconv.Atoi(age[:len(age)-1])
+		if err != nil {
+			return fmt.Errorf("FileWriter - Invalid maxAge: %v", err)
+		}
+		fw.MaxAge = time.Hour * 24 * time.Duration(n)
+		return nil
+	}
+
+	d, err := time.ParseDuration(age)
+	if err != nil {
+		return fmt.Errorf("FileWriter - Invalid maxAge: %v", err)
+	}
+	fw.MaxAge = d
+	return nil
+}
+
+// SetFlushInterval set the flush interval of the write buffer
+func (fw *FileWriter) SetFlushInterval(interval string) error {
+	fi, err := time.ParseDuration(interval)
+	if err != nil {
+		return fmt.Errorf("FileWriter - Invalid flushInterval: %v", err)
+	}
+	fw.FlushInterval = fi
+	return nil
+}
+
+// SetCompress set the compressor name
+func (fw *FileWriter) SetCompress(name string) error {
+	if name != "" && GetCompressor(name) == nil {
+		return fmt.Errorf("FileWriter - Invalid compress: %v", name)
+	}
+	fw.Compress = name
+	return nil
+}
+
+// SetCompressDelay set the compress delay
+func (fw *FileWriter) SetCompressDelay(delay string) error {
+	d, err := time.ParseDuration(delay)
+	if err != nil {
+		return fmt.Errorf("FileWriter - Invalid compressDelay: %v", err)
+	}
+	fw.CompressDelay = d
+	return nil
+}
+
 // SetFormat set the log formatter
 func (fw *FileWriter) SetFormat(format string) {
 	fw.Logfmt = NewLogFormatter(format)
 }
 
 // SetFilter set the log filter
-func (fw *FileWriter) SetFilter(filter string) {
-	fw.Logfil = NewLogFilter(filter)
+func (fw *FileWriter) SetFilter(filter string) error {
+	f, err := ParseLogFilter(filter)
+	if err != nil {
+		return fmt.Errorf("FileWriter - %v", err)
+	}
+	fw.Logfil = f
+	return nil
+}
+
+// stats return the statistics of the writer
+func (fw *FileWriter) stats() *writerStat {
+	return &fw.wstat
 }
 
 // Write write logger message into file.
 func (fw *FileWriter) Write(le *Event) {
-	if fw.Logfil != nil && fw.Logfil.Reject(le) {
+	if reject(&fw.wstat, fw.Logfil, le) {
 		return
 	}
 
@@ -67,13 +175,23 @@ func (fw *FileWriter) Write(le *Event) {
 		}
 	}
 
+	fw.mutex.Lock()
+	defer fw.mutex.Unlock()
+
 	fw.init()
 	if fw.file == nil {
 		return
 	}
 
+	if fw.Shared {
+		fw.syncFileSize()
+	}
+
 	if fw.fileSize > 0 && fw.needRotate(le) {
 		fw.rotate(le.When)
+		if fw.file == nil {
+			return
+		}
 	}
 
 	// format msg
@@ -81,50 +199,120 @@ func (fw *FileWriter) Write(le *Event) {
 	lf.Write(&fw.bb, le)
 
 	// write log
-	n, err := fw.file.Write(fw.bb.Bytes())
+	var w io.Writer = fw.file
+	if fw.bw != nil {
+		// write the whole record, a record is never split into 2 writes of the file
+		if fw.bw.Available() < fw.bb.Len() {
+			fw.flushBuffer()
+		}
+		w = fw.bw
+	}
+
+	n, err := w.Write(fw.bb.Bytes())
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "FileWriter(%q) - Write(): %v\n", fw.Path, err)
+		writerErrorf(fw, "FileWriter(%q) - Write(): %v\n", fw.Path, err)
 	}
 	fw.fileSize += int64(n)
 
 	if le.Level <= fw.SyncLevel {
+		fw.flushBuffer()
 		err := fw.file.Sync()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "FileWriter(%q) - Sync(): %v\n", fw.Path, err)
+			writerErrorf(fw, "FileWriter(%q) - Sync(): %v\n", fw.Path, err)
 		}
 	}
 }
 
 // Flush flush file logger.
-// there are no buffering messages in file logger in memory.
-// flush file means sync file from disk.
+// write the buffered messages to the file,
+// and sync file to disk.
 func (fw *FileWriter) Flush() {
+	fw.mutex.Lock()
+	defer fw.mutex.Unlock()
+
 	if fw.file != nil {
+		fw.flushBuffer()
 		err := fw.file.Sync()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "FileWriter(%q) - Sync(): %v\n", fw.Path, err)
+			writerErrorf(fw, "FileWriter(%q) - Sync(): %v\n", fw.Path, err)
 		}
 	}
 }
 
 // Close close the file description, close file writer.
 func (fw *FileWriter) Close() {
+	fw.mutex.Lock()
+
+	if fw.done != nil {
+		close(fw.done)
+		fw.done = nil
+	}
+	fw.close()
+
+	// stop the file worker, the rotated files which are not due (CompressDelay)
+	// are left uncompressed, and compressed at the next startup
+	tdone := fw.tdone
+	if fw.tasks != nil {
+		close(fw.tasks)
+		fw.tasks, fw.tdone = nil, nil
+	}
+
+	fw.mutex.Unlock()
+
+	// wait for the due tasks to be done (out of the lock)
+	if tdone != nil {
+		<-tdone
+	}
+}
+
+// close write the buffered messages and close the file
+func (fw *FileWriter) close() {
 	if fw.file != nil {
+		fw.flushBuffer()
 		err := fw.file.Close()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "FileWriter(%q) - Close(): %v\n", fw.Path, err)
+			writerErrorf(fw, "FileWriter(%q) - Close(): %v\n", fw.Path, err)
 		}
 		fw.file = nil
 	}
 }
 
+// flushBuffer write the buffered messages to the file
+func (fw *FileWriter) flushBuffer() {
+	if fw.bw != nil && fw.bw.Buffered() > 0 {
+		if err := fw.bw.Flush(); err != nil {
+			writerErrorf(fw, "FileWriter(%q) - Flush(): %v\n", fw.Path, err)
+		}
+	}
+}
+
+// run flush the write buffer at every FlushInterval (goroutine)
+func (fw *FileWriter) run(done chan struct{}) {
+	ticker := time.NewTicker(fw.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			fw.mutex.Lock()
+			if fw.file != nil {
+				fw.flushBuffer()
+			}
+			fw.mutex.Unlock()
+		case <-done:
+			return
+		}
+	}
+}
+
 func (fw *FileWriter) init() {
 	if fw.file != nil {
 		return
 	}
 
 	// init dir, prefix, suffix
-	if fw.prefix == "" {
+	startup := fw.prefix == ""
+	if startup {
 		fw.dir, fw.prefix = filepath.Split(fw.Path)
 		fw.suffix = filepath.Ext(fw.prefix)
 		if fw.suffix == "" {
@@ -148,7 +336,7 @@ func (fw *FileWriter) init() {
 	// Open the log file
 	file, err := os.OpenFile(fw.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, os.FileMode(fw.FilePerm))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "FileWriter(%q) - OpenFile(): %v\n", fw.Path, err)
+		writerErrorf(fw, "FileWriter(%q) - OpenFile(): %v\n", fw.Path, err)
 		return
 	}
 
@@ -157,7 +345,7 @@ func (fw *FileWriter) init() {
 
 	fi, err := file.Stat()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "FileWriter(%q) - Stat(): %v\n", fw.Path, err)
+		writerErrorf(fw, "FileWriter(%q) - Stat(): %v\n", fw.Path, err)
 		return
 	}
 
@@ -168,6 +356,107 @@ func (fw *FileWriter) init() {
 	fw.openHour = fw.openTime.Hour()
 
 	fw.file = file
+
+	// init write buffer
+	if fw.BufferSize > 0 {
+		if fw.bw == nil {
+			fw.bw = bufio.NewWriterSize(file, fw.BufferSize)
+		} else {
+			fw.bw.Reset(file)
+		}
+
+		if fw.FlushInterval > 0 && fw.done == nil {
+			fw.done = make(chan struct{})
+			go fw.run(fw.done)
+		}
+	}
+
+	if startup {
+		// recover the half-compressed files left by a crash
+		if fw.compressor() != nil {
+			fw.addTask(fileTask{kind: taskRecover, due: time.Now()})
+		}
+
+		// apply the retention policies at startup
+		if fw.MaxTotalSize > 0 || fw.MaxAge > 0 {
+			fw.addTask(fileTask{kind: taskDelete, size: fw.fileSize})
+		}
+	}
+}
+
+// reopen close the current log file and open the log file again
+func (fw *FileWriter) reopen() {
+	fw.close()
+	fw.init()
+}
+
+// isRotated check the log file is rotated (renamed or deleted) by other process
+func (fw *FileWriter) isRotated() bool {
+	fi, err := os.Stat(fw.Path)
+	if err != nil {
+		return true
+	}
+
+	ofi, err := fw.file.Stat()
+	if err != nil {
+		return true
+	}
+
+	return !os.SameFile(fi, ofi)
+}
+
+// syncFileSize sync the file size with the actual size of the opened log file (Shared mode).
+// The size is got by seeking to the end of the file (the log file path is checked only on rotation),
+// if the log file is rotated by other process, it's size exceeds or it's time is expired, so it's reopened by rotate().
+func (fw *FileWriter) syncFileSize() {
+	size, err := fw.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		writerErrorf(fw, "FileWriter(%q) - Seek(): %v\n", fw.Path, err)
+		return
+	}
+
+	fw.fileSize = size
+	if fw.bw != nil {
+		fw.fileSize += int64(fw.bw.Buffered())
+	}
+}
+
+// lock acquire the rotation lock file (Shared mode).
+// It never waits (the loggers are blocked by the writer), if the lock file is locked by other process,
+// the rotation is skipped and retried at the next write. An error is reported if the lock file
+// is kept locked over fileLockTimeout.
+func (fw *FileWriter) lock() bool {
+	lp := fw.Path + ".lock"
+
+	f, err := lockFile(lp, os.FileMode(fw.FilePerm))
+	if err == nil {
+		fw.lockf = f
+		fw.lockw = time.Time{}
+		return true
+	}
+
+	if err == errFileLocked {
+		if fw.lockw.IsZero() {
+			fw.lockw = time.Now()
+			return false
+		}
+		if time.Since(fw.lockw) < fileLockTimeout {
+			return false
+		}
+		err = fmt.Errorf("locked over %v", fileLockTimeout)
+		fw.lockw = time.Time{}
+	}
+
+	writerErrorf(fw, "FileWriter(%q) - lockFile(%q): %v\n", fw.Path, lp, err)
+	return false
+}
+
+// unlock release the rotation lock file (Shared mode)
+func (fw *FileWriter) unlock() {
+	if err := unlockFile(fw.lockf); err != nil {
+		writerErrorf(fw, "FileWriter(%q) - unlockFile(%q): %v\n", fw.Path, fw.lockf.Name(), err)
+	}
+	fw.lockf = nil
 }
 
 func (fw *FileWriter) needRotate(le *Event) bool {
@@ -179,6 +468,19 @@ func (fw *FileWriter) needRotate(le *Event) bool {
 // DoRotate means it need to write file in new file.
 // new file name like xx-20130101.log (daily) or xx-001.log (by line or size)
 func (fw *FileWriter) rotate(tm time.Time) {
+	if fw.Shared {
+		if !fw.lock() {
+			return
+		}
+		defer fw.unlock()
+
+		// the log file is rotated by other process
+		if fw.isRotated() {
+			fw.reopen()
+			return
+		}
+	}
+
 	path := "" // rotate file name
 
 	date := ""
@@ -209,9 +511,10 @@ func (fw *FileWriter) rotate(tm time.Time) {
 	}
 
 	// close file before rename
+	fw.flushBuffer()
 	err := fw.file.Close()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "FileWriter(%q) - Close(): %v\n", fw.Path, err)
+		writerErrorf(fw, "FileWriter(%q) - Close(): %v\n", fw.Path, err)
 		return
 	}
 	fw.file = nil
@@ -220,30 +523,36 @@ func (fw *FileWriter) rotate(tm time.Time) {
 	// even if occurs error,we MUST guarantee to  restart new logger
 	err = os.Rename(fw.Path, path)
 	if err == nil {
-		if fw.Gzip {
-			go fw.compressFile(path)
+		if fw.compressor() != nil {
+			fw.addTask(fileTask{kind: taskCompress, path: path, due: time.Now().Add(fw.CompressDelay)})
 		}
 	} else {
-		fmt.Fprintf(os.Stderr, "FileWriter(%q) - Rename(->%q): %v\n", fw.Path, path, err)
+		writerErrorf(fw, "FileWriter(%q) - Rename(->%q): %v\n", fw.Path, path, err)
 	}
 
 	// Open file again
 	fw.init()
 
-	// delete outdated rotated files
-	if fw.MaxHours > 0 || fw.MaxDays > 0 {
-		go fw.deleteOutdatedFiles()
+	// delete the outdated rotated files and apply the retention policies (reserve MaxSize for the current log file)
+	if fw.MaxHours > 0 || fw.MaxDays > 0 || fw.MaxTotalSize > 0 || fw.MaxAge > 0 {
+		size := fw.fileSize
+		if size < fw.MaxSize {
+			size = fw.MaxSize
+		}
+		fw.addTask(fileTask{kind: taskDelete, size: size})
 	}
 }
 
 func (fw *FileWriter) nextFile(pre string) string {
+	c := fw.compressor()
+
 	var path string
 	for fw.fileNum++; ; fw.fileNum++ {
 		path = pre + fmt.Sprintf("-%03d", fw.fileNum) + fw.suffix
 		_, err := os.Stat(path)
 		if os.IsNotExist(err) {
-			if fw.Gzip {
-				p := path + ".gz"
+			if c != nil {
+				p := path + c.Ext()
 				_, err = os.Stat(p)
 				if os.IsNotExist(err) {
 					break
@@ -260,62 +569,228 @@ func (fw *FileWriter) nextFile(pre string) string {
 			p := pre + fmt.Sprintf("-%03d", i) + fw.suffix
 			err := os.Remove(p)
 			if os.IsNotExist(err) {
-				if fw.Gzip {
-					pg := path + ".gz"
-					err = os.Remove(p)
+				if c != nil {
+					pg := p + c.Ext()
+					err = os.Remove(pg)
 					if os.IsNotExist(err) {
 						break
 					} else if err != nil {
-						fmt.Fprintf(os.Stderr, "FileWriter(%q) - Remove(%q): %v\n", fw.Path, pg, err)
+						writerErrorf(fw, "FileWriter(%q) - Remove(%q): %v\n", fw.Path, pg, err)
 					}
 				} else {
 					break
 				}
 			} else if err != nil {
-				fmt.Fprintf(os.Stderr, "FileWriter(%q) - Remove(%q): %v\n", fw.Path, p, err)
+				writerErrorf(fw, "FileWriter(%q) - Remove(%q): %v\n", fw.Path, p, err)
 			}
 		}
 	}
 	return path
 }
 
-func (fw *FileWriter) compressFile(src string) {
-	dst := src + ".gz"
+// compressor return the compressor of the rotated files, nil if compression is disabled
+func (fw *FileWriter) compressor() Compressor {
+	if fw.Compress != "" {
+		return GetCompressor(fw.Compress)
+	}
+	if fw.Gzip {
+		return GetCompressor("gzip")
+	}
+	return nil
+}
+
+// addTask add a task to the background file worker (start it if not started).
+// It never blocks, the task is skipped if the task queue is full
+// (the file is compressed or deleted at the next rotation or startup).
+func (fw *FileWriter) addTask(ft fileTask) {
+	if fw.tasks == nil {
+		fw.tasks = make(chan fileTask, 1000)
+		fw.tdone = make(chan struct{})
+		go fw.worker(fw.compressor(), fw.tasks, fw.tdone)
+	}
+
+	select {
+	case fw.tasks <- ft:
+	default:
+		writerErrorf(fw, "FileWriter(%q) - addTask(%d, %q): task queue is full\n", fw.Path, ft.kind, ft.path)
+	}
+}
+
+// worker compress and delete the rotated files one by one (goroutine).
+// The compress tasks are pending until the due time, the delete tasks are executed immediately.
+// If the task channel is closed, the pending compress tasks which are not due are skipped.
+func (fw *FileWriter) worker(c Compressor, tasks chan fileTask, done chan struct{}) {
+	defer close(done)
+
+	var pending []fileTask // the compress tasks ordered by the due time
+	for {
+		var timer *time.Timer
+		var tc <-chan time.Time
+		if len(pending) > 0 {
+			timer = time.NewTimer(time.Until(pending[0].due))
+			tc = timer.C
+		}
+
+		select {
+		case ft, ok := <-tasks:
+			if timer != nil {
+				timer.Stop()
+			}
+
+			if !ok {
+				now := time.Now()
+				for _, ct := range pending {
+					if !ct.due.After(now) {
+						fw.compressFile(c, ct.path)
+					}
+				}
+				return
+			}
+
+			switch ft.kind {
+			case taskRecover:
+				pending = addPendingTasks(pending, fw.recoverCompressedFiles(c, ft.due)...)
+			case taskCompress:
+				pending = addPendingTasks(pending, ft)
+			case taskDelete:
+				fw.deleteFiles(ft.size)
+			}
+		case <-tc:
+			ct := pending[0]
+			pending = pending[1:]
+			fw.compressFile(c, ct.path)
+		}
+	}
+}
+
+// addPendingTasks add the tasks 'fts' to the pending tasks 'pts' ordered by the due time
+func addPendingTasks(pts []fileTask, fts ...fileTask) []fileTask {
+	pts = append(pts, fts...)
+	sort.SliceStable(pts, func(i, j int) bool {
+		return pts[i].due.Before(pts[j].due)
+	})
+	return pts
+}
+
+func (fw *FileWriter) compressFile(c Compressor, src string) {
+	dst := src + c.Ext()
+	tmp := dst + ".tmp"
 
 	f, err := os.Open(src)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "FileWriter(%q) - Open(%q): %v\n", fw.Path, src, err)
+		// deleted by the retention policies or compressed by other process
+		if !os.IsNotExist(err) {
+			writerErrorf(fw, "FileWriter(%q) - Open(%q): %v\n", fw.Path, src, err)
+		}
 		return
 	}
 	defer f.Close()
 
-	// If this file already exists, we presume it was created by
-	// a previous attempt to compress the log file.
-	gzf, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(fw.FilePerm))
+	fi, err := f.Stat()
+	if err != nil {
+		writerErrorf(fw, "FileWriter(%q) - Stat(%q): %v\n", fw.Path, src, err)
+		return
+	}
+
+	// Compress to a temporary file and rename it after completed,
+	// so a half-compressed file is never left as the destination file by a crash.
+	cf, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(fw.FilePerm))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "FileWriter(%q) - OpenFile(%q): %v\n", fw.Path, dst, err)
+		writerErrorf(fw, "FileWriter(%q) - OpenFile(%q): %v\n", fw.Path, tmp, err)
 		return
 	}
-	defer gzf.Close()
+	defer cf.Close()
 
-	gz := gzip.NewWriter(gzf)
+	cw, err := c.NewWriter(cf)
+	if err != nil {
+		writerErrorf(fw, "FileWriter(%q) - NewWriter(%q): %v\n", fw.Path, tmp, err)
+		return
+	}
 
-	if _, err := io.Copy(gz, f); err != nil {
-		fmt.Fprintf(os.Stderr, "FileWriter(%q) - gzip(%q): %v\n", fw.Path, dst, err)
+	if _, err := io.Copy(cw, f); err != nil {
+		writerErrorf(fw, "FileWriter(%q) - Compress(%q): %v\n", fw.Path, tmp, err)
 		return
 	}
-	if err := gz.Close(); err != nil {
-		fmt.Fprintf(os.Stderr, "FileWriter(%q) - gzip.Close(%q): %v\n", fw.Path, dst, err)
+	if err := cw.Close(); err != nil {
+		writerErrorf(fw, "FileWriter(%q) - Compress.Close(%q): %v\n", fw.Path, tmp, err)
 		return
 	}
-	if err := gzf.Close(); err != nil {
-		fmt.Fprintf(os.Stderr, "FileWriter(%q) - Close(%q): %v\n", fw.Path, dst, err)
+	if err := cf.Close(); err != nil {
+		writerErrorf(fw, "FileWriter(%q) - Close(%q): %v\n", fw.Path, tmp, err)
+		return
+	}
+
+	// keep the modified time of the rotated file for the retention policies
+	if err := os.Chtimes(tmp, fi.ModTime(), fi.ModTime()); err != nil {
+		writerErrorf(fw, "FileWriter(%q) - Chtimes(%q): %v\n", fw.Path, tmp, err)
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		writerErrorf(fw, "FileWriter(%q) - Rename(%q->%q): %v\n", fw.Path, tmp, dst, err)
 		return
 	}
 
 	f.Close()
 	if err := os.Remove(src); err != nil {
-		fmt.Fprintf(os.Stderr, "FileWriter(%q) - Remove(%q): %v\n", fw.Path, src, err)
+		writerErrorf(fw, "FileWriter(%q) - Remove(%q): %v\n", fw.Path, src, err)
+	}
+}
+
+// recoverCompressedFiles recover the rotated files (modified before the startup time 'due')
+// which are not compressed completely by a crash.
+// - remove the stale temporary compressing files
+// - remove the source file if the compressed file is valid, otherwise remove the compressed file and compress again
+// - return the compress tasks of the uncompressed rotated files (due after CompressDelay)
+func (fw *FileWriter) recoverCompressedFiles(c Compressor, due time.Time) (cts []fileTask) {
+	fis, err := fw.listRotatedFiles()
+	if err != nil {
+		writerErrorf(fw, "FileWriter(%q) - listRotatedFiles(%q): %v\n", fw.Path, fw.dir, err)
+		return
+	}
+
+	// oldest first
+	for i := len(fis) - 1; i >= 0; i-- {
+		fi := fis[i]
+
+		name := fi.Name()
+		if !strings.HasSuffix(name, fw.suffix) || fi.ModTime().After(due) {
+			continue
+		}
+
+		src := filepath.Join(fw.dir, name)
+		dst := src + c.Ext()
+		tmp := dst + ".tmp"
+		if tfi, err := os.Stat(tmp); err == nil {
+			if time.Since(tfi.ModTime()) < fileLockTimeout {
+				// compressing by other process
+				continue
+			}
+			os.Remove(tmp)
+		}
+
+		if _, err := os.Stat(dst); err == nil {
+			if err := checkCompressedFile(c, dst); err == nil {
+				if err := os.Remove(src); err != nil {
+					writerErrorf(fw, "FileWriter(%q) - Remove(%q): %v\n", fw.Path, src, err)
+				}
+				continue
+			}
+			os.Remove(dst)
+		}
+
+		cts = append(cts, fileTask{kind: taskCompress, path: src, due: fi.ModTime().Add(fw.CompressDelay)})
+	}
+	return
+}
+
+// deleteFiles delete the outdated rotated files (MaxHours, MaxDays) and
+// the rotated files which exceed MaxAge or MaxTotalSize.
+// size: the reserved size of the current log file
+func (fw *FileWriter) deleteFiles(size int64) {
+	if fw.MaxHours > 0 || fw.MaxDays > 0 {
+		fw.deleteOutdatedFiles()
+	}
+	if fw.MaxTotalSize > 0 || fw.MaxAge > 0 {
+		fw.deleteExceededFiles(size)
 	}
 }
 
@@ -329,14 +804,14 @@ func (fw *FileWriter) deleteOutdatedFiles() {
 
 	f, err := os.Open(fw.dir)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "FileWriter(%q) - Open(%q): %v\n", fw.Path, fw.dir, err)
+		writerErrorf(fw, "FileWriter(%q) - Open(%q): %v\n", fw.Path, fw.dir, err)
 		return
 	}
 	defer f.Close()
 
 	fis, err := f.Readdir(-1)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "FileWriter(%q) - Readdir(%q): %v\n", fw.Path, fw.dir, err)
+		writerErrorf(fw, "FileWriter(%q) - Readdir(%q): %v\n", fw.Path, fw.dir, err)
 		return
 	}
 
@@ -350,13 +825,125 @@ func (fw *FileWriter) deleteOutdatedFiles() {
 			if strings.HasPrefix(name, fw.prefix) {
 				path := filepath.Join(fw.dir, fi.Name())
 				if err := os.Remove(path); err != nil {
-					fmt.Fprintf(os.Stderr, "FileWriter(%q) - Remove(%q): %v\n", fw.Path, path, err)
+					writerErrorf(fw, "FileWriter(%q) - Remove(%q): %v\n", fw.Path, path, err)
 				}
 			}
 		}
 	}
 }
 
+// listRotatedFiles list the rotated files (compressed or not), sorted by the modified time (newest first)
+func (fw *FileWriter) listRotatedFiles() ([]os.FileInfo, error) {
+	f, err := os.Open(fw.dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fis, err := f.Readdir(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	var rfs []os.FileInfo
+	for _, fi := range fis {
+		if !fi.IsDir() && fw.isRotatedName(fi.Name()) {
+			rfs = append(rfs, fi)
+		}
+	}
+
+	sort.Slice(rfs, func(i, j int) bool {
+		return rfs[i].ModTime().After(rfs[j].ModTime())
+	})
+	return rfs, nil
+}
+
+// isRotatedName check the file name has the exact shape of the rotated file name:
+// prefix[-date][-NNN]suffix[.ext], the date (-2006010215 hourly, -20060102 daily) is required if
+// MaxHours or MaxDays is set, the split number -NNN (3+ digits) is required if the date is absent.
+func (fw *FileWriter) isRotatedName(name string) bool {
+	n := 0 // date digits
+	if fw.MaxHours > 0 {
+		n = 10
+	} else if fw.MaxDays > 0 {
+		n = 8
+	}
+	return isRotatedName(fw.prefix, fw.suffix, name, n)
+}
+
+// IsRotatedName check the file name has the shape of the rotated (and compressed) file name
+// of the log file 'path' written by FileWriter (hourly, daily or split by size).
+func IsRotatedName(path, name string) bool {
+	prefix := filepath.Base(path)
+	suffix := filepath.Ext(prefix)
+	if suffix == "" {
+		suffix = ".log"
+	} else {
+		prefix = strings.TrimSuffix(prefix, suffix)
+	}
+
+	return isRotatedName(prefix, suffix, name, 0) || isRotatedName(prefix, suffix, name, 8) || isRotatedName(prefix, suffix, name, 10)
+}
+
+// isRotatedName check the file name has the shape prefix[-date][-NNN]suffix[.ext],
+// n: the digits of the required date, 0 for no date.
+func isRotatedName(prefix, suffix, name string, n int) bool {
+	if c := GetCompressorByExt(name); c != nil {
+		name = name[:len(name)-len(c.Ext())]
+	}
+	if len(name) <= len(prefix)+len(suffix) || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+		return false
+	}
+
+	mid := name[len(prefix) : len(name)-len(suffix)]
+	if n > 0 {
+		if len(mid) < n+1 || mid[0] != '-' || !isDigits(mid[1:n+1]) {
+			return false
+		}
+		mid = mid[n+1:]
+		if mid == "" {
+			return true
+		}
+	}
+
+	return len(mid) > 3 && mid[0] == '-' && isDigits(mid[1:])
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// deleteExceededFiles delete the rotated files which exceed MaxAge or MaxTotalSize.
+// size: the reserved size of the current log file
+func (fw *FileWriter) deleteExceededFiles(size int64) {
+	fis, err := fw.listRotatedFiles()
+	if err != nil {
+		writerErrorf(fw, "FileWriter(%q) - listRotatedFiles(%q): %v\n", fw.Path, fw.dir, err)
+		return
+	}
+
+	var due time.Time
+	if fw.MaxAge > 0 {
+		due = time.Now().Add(-fw.MaxAge)
+	}
+
+	total := size
+	for _, fi := range fis {
+		total += fi.Size()
+		if (fw.MaxAge > 0 && fi.ModTime().Before(due)) || (fw.MaxTotalSize > 0 && total > fw.MaxTotalSize) {
+			path := filepath.Join(fw.dir, fi.Name())
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				writerErrorf(fw, "FileWriter(%q) - Remove(%q): %v\n", fw.Path, path, err)
+			}
+		}
+	}
+}
+
 func init() {
 	RegisterWriter("file", func() Writer {
 		return &FileWriter{}
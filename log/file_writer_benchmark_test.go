@@ -0,0 +1,36 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func benchmarkFileWriter(b *testing.B, fw *FileWriter) {
+	dir := filepath.Dir(fw.Path)
+	os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	lg := NewLog()
+	lg.SetFormatter(TextFmtSimple)
+	le := newEvent(lg, LevelInfo, "hello benchmark test")
+
+	b.ResetTimer()
+	for N := 0; N < b.N; N++ {
+		fw.Write(le)
+	}
+	fw.Close()
+}
+
+func BenchmarkFileWriter(b *testing.B) {
+	benchmarkFileWriter(b, &FileWriter{Path: "BenchmarkFileWriter/filetest.log"})
+}
+
+func BenchmarkFileWriterBuffered(b *testing.B) {
+	benchmarkFileWriter(b, &FileWriter{Path: "BenchmarkFileWriterBuffered/filetest.log", BufferSize: 64 * 1024})
+}
+
+func BenchmarkFileWriterBufferedFlushInterval(b *testing.B) {
+	benchmarkFileWriter(b, &FileWriter{Path: "BenchmarkFileWriterBufferedFlushInterval/filetest.log", BufferSize: 64 * 1024, FlushInterval: time.Millisecond * 100})
+}
@@ -45,6 +45,9 @@ func TestFileCallerGlobal(t *testing.T) {
 	os.RemoveAll(dir)
 	defer os.RemoveAll(dir)
 
+	// detach the file writer from the global log, the later tests use the global log
+	defer SetWriter(nil)
+
 	SetFormatter(NewTextFormatter("%l %S:%L %F() - %m%n%T"))
 	SetWriter(&FileWriter{Path: path})
 	file, line, ffun := testGetCaller(1)
@@ -468,7 +471,7 @@ func TestFileRotateDailyOutdated(t *testing.T) {
 		}
 		tm = tm.Add(time.Hour * 24)
 
-		// let deleteOutdatedFiles goroutine finish
+		// let the file worker finish deleteOutdatedFiles
 		time.Sleep(time.Millisecond * 100)
 	}
 	fw.Close()
@@ -584,7 +587,7 @@ func TestFileRotateHourlyOutdated(t *testing.T) {
 		}
 		tm = tm.Add(time.Hour)
 
-		// let deleteOutdatedFiles goroutine finish
+		// let the file worker finish deleteOutdatedFiles
 		time.Sleep(time.Millisecond * 100)
 	}
 	fw.Close()
@@ -624,3 +627,644 @@ func TestFileRotateHourlyOutdated(t *testing.T) {
 		t.Errorf("TestFileRotateHourlyOutdated\nexpect: %q, actual %q", e, a)
 	}
 }
+
+func TestFileRetentionStartup(t *testing.T) {
+	path := "TestFileRetentionStartup/filetest.log"
+	dir := filepath.Dir(path)
+	os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	os.MkdirAll(dir, 0770)
+
+	now := time.Now()
+	for i := 1; i <= 5; i++ {
+		sp := strings.ReplaceAll(path, ".log", fmt.Sprintf("-%03d.log", i))
+		if i%2 == 0 {
+			sp += ".gz"
+		}
+		ioutil.WriteFile(sp, []byte("0123456789"), 0660)
+		tm := now.Add(time.Hour * 24 * time.Duration(i-6))
+		os.Chtimes(sp, tm, tm)
+	}
+	ioutil.WriteFile(filepath.Join(dir, "other.log"), []byte("0123456789"), 0660)
+	os.Chtimes(filepath.Join(dir, "other.log"), now.Add(time.Hour*-240), now.Add(time.Hour*-240))
+
+	fw := &FileWriter{}
+	if err := fw.SetMaxAge("3d"); err != nil {
+		t.Fatal(err)
+	}
+	fw.Path = path
+
+	lg := NewLog()
+	lg.SetFormatter(TextFmtSimple)
+	lg.SetWriter(fw)
+	lg.Info("hello")
+
+	// let the file worker finish deleteExceededFiles
+	time.Sleep(time.Millisecond * 100)
+	lg.Close()
+
+	for i := 1; i <= 5; i++ {
+		sp := strings.ReplaceAll(path, ".log", fmt.Sprintf("-%03d.log", i))
+		if i%2 == 0 {
+			sp += ".gz"
+		}
+
+		err := iox.FileExists(sp)
+		if i <= 3 && err == nil {
+			t.Errorf("TestFileRetentionStartup file %q exists", sp)
+		}
+		if i > 3 && err != nil {
+			t.Errorf("TestFileRetentionStartup file %q not exists: %v", sp, err)
+		}
+	}
+
+	if err := iox.FileExists(filepath.Join(dir, "other.log")); err != nil {
+		t.Errorf("TestFileRetentionStartup file other.log not exists: %v", err)
+	}
+}
+
+func TestFileRetentionMaxTotalSize(t *testing.T) {
+	path := "TestFileRetentionMaxTotalSize/filetest.log"
+	dir := filepath.Dir(path)
+	os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	lg := NewLog()
+	lg.SetFormatter(TextFmtSimple)
+	lg.SetWriter(&FileWriter{Path: path, MaxSize: 10, MaxTotalSize: 80})
+	for i := 1; i < 10; i++ {
+		lg.Info("hello test ", i)
+
+		// let the file worker finish deleteExceededFiles and make the modified time different
+		time.Sleep(time.Millisecond * 20)
+	}
+	lg.Close()
+
+	// each file is 17 bytes: MaxSize(10) + 4 rotated files <= 80
+	total := int64(0)
+	for i := 1; i < 9; i++ {
+		sp := strings.ReplaceAll(path, ".log", fmt.Sprintf("-%03d.log", i))
+		fi, err := os.Stat(sp)
+		if i < 5 && err == nil {
+			t.Errorf("TestFileRetentionMaxTotalSize file %q exists", sp)
+		}
+		if i >= 5 {
+			if err != nil {
+				t.Errorf("TestFileRetentionMaxTotalSize file %q not exists: %v", sp, err)
+			} else {
+				total += fi.Size()
+			}
+		}
+	}
+
+	if total > 80-10 {
+		t.Errorf("TestFileRetentionMaxTotalSize total size %d > 70", total)
+	}
+}
+
+func TestFileRetentionCompressDelay(t *testing.T) {
+	path := "TestFileRetentionCompressDelay/filetest.log"
+	dir := filepath.Dir(path)
+	os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	// the delete tasks are not blocked by the pending compress tasks
+	lg := NewLog()
+	lg.SetFormatter(TextFmtSimple)
+	lg.SetWriter(&FileWriter{Path: path, MaxSize: 10, MaxTotalSize: 80, Gzip: true, CompressDelay: time.Hour})
+	for i := 1; i < 10; i++ {
+		lg.Info("hello test ", i)
+
+		// let the file worker finish deleteExceededFiles and make the modified time different
+		time.Sleep(time.Millisecond * 20)
+	}
+
+	for i := 1; i < 9; i++ {
+		sp := strings.ReplaceAll(path, ".log", fmt.Sprintf("-%03d.log", i))
+		err := iox.FileExists(sp)
+		if i < 5 && err == nil {
+			t.Errorf("TestFileRetentionCompressDelay file %q exists", sp)
+		}
+		if i >= 5 && err != nil {
+			t.Errorf("TestFileRetentionCompressDelay file %q not exists: %v", sp, err)
+		}
+	}
+	lg.Close()
+}
+
+func TestFileIsRotatedName(t *testing.T) {
+	cs := []struct {
+		fw   *FileWriter
+		name string
+		want bool
+	}{
+		{&FileWriter{}, "app-001.log", true},
+		{&FileWriter{}, "app-1234.log.gz", true},
+		{&FileWriter{}, "app.log", false},
+		{&FileWriter{}, "app-01.log", false},
+		{&FileWriter{}, "app-error.log", false},
+		{&FileWriter{}, "app-error-001.log", false},
+		{&FileWriter{MaxDays: 7}, "app-20240102.log", true},
+		{&FileWriter{MaxDays: 7}, "app-20240102-002.log.gz", true},
+		{&FileWriter{MaxDays: 7}, "app-001.log", false},
+		{&FileWriter{MaxDays: 7}, "app-error-20240102.log", false},
+		{&FileWriter{MaxDays: 7}, "app-2024010203.log", false},
+		{&FileWriter{MaxHours: 7}, "app-2024010203.log", true},
+		{&FileWriter{MaxHours: 7}, "app-20240102.log", false},
+	}
+
+	for i, c := range cs {
+		c.fw.prefix, c.fw.suffix = "app", ".log"
+		if a := c.fw.isRotatedName(c.name); a != c.want {
+			t.Errorf("[%d] isRotatedName(%q) = %v, want %v", i, c.name, a, c.want)
+		}
+	}
+}
+
+func TestFileSharedRotate(t *testing.T) {
+	path := "TestFileSharedRotate/filetest.log"
+	dir := filepath.Dir(path)
+	os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	// stale lock file left by a crashed process
+	os.MkdirAll(dir, 0770)
+	ioutil.WriteFile(path+".lock", []byte{}, 0660)
+	tm := time.Now().Add(-fileLockTimeout * 2)
+	os.Chtimes(path+".lock", tm, tm)
+
+	lg := NewLog()
+	lg.SetFormatter(TextFmtSimple)
+
+	// 2 writers (processes) write to the same file
+	fw1 := &FileWriter{Path: path, MaxSize: 40, Shared: true}
+	fw2 := &FileWriter{Path: path, MaxSize: 40, Shared: true}
+	for i := 1; i <= 20; i++ {
+		le := newEvent(lg, LevelInfo, fmt.Sprintf("hello test %02d", i))
+		if i%2 == 0 {
+			fw2.Write(le)
+		} else {
+			fw1.Write(le)
+		}
+	}
+	fw1.Close()
+	fw2.Close()
+
+	fis, _ := ioutil.ReadDir(dir)
+
+	var lines []string
+	for _, fi := range fis {
+		if strings.HasSuffix(fi.Name(), ".lock") {
+			t.Errorf("TestFileSharedRotate lock file %q exists", fi.Name())
+			continue
+		}
+
+		bs, _ := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+		if len(bs) > 40+18 {
+			t.Errorf("TestFileSharedRotate file %q size %d exceeds", fi.Name(), len(bs))
+		}
+		lines = append(lines, strings.Split(strings.TrimSpace(string(bs)), eol)...)
+	}
+	sort.Strings(lines)
+
+	var expect []string
+	for i := 1; i <= 20; i++ {
+		expect = append(expect, fmt.Sprintf("[I] hello test %02d", i))
+	}
+	if !reflect.DeepEqual(expect, lines) {
+		t.Errorf("TestFileSharedRotate\nexpect: %q\nactual: %q", expect, lines)
+	}
+}
+
+func TestFileRecoverCompressedFiles(t *testing.T) {
+	path := "TestFileRecoverCompressedFiles/filetest.log"
+	dir := filepath.Dir(path)
+	os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	os.MkdirAll(dir, 0770)
+
+	sp := func(i int) string {
+		return strings.ReplaceAll(path, ".log", fmt.Sprintf("-%03d.log", i))
+	}
+	data := func(i int) string {
+		return fmt.Sprintf("[I] hello test %d%s", i, eol)
+	}
+
+	// 1: uncompressed
+	ioutil.WriteFile(sp(1), []byte(data(1)), 0660)
+
+	// 2: half-compressed
+	ioutil.WriteFile(sp(2), []byte(data(2)), 0660)
+	ioutil.WriteFile(sp(2)+".gz", []byte{0x1f, 0x8b, 0x08}, 0660)
+
+	// 3: compressed but the source file is not removed
+	ioutil.WriteFile(sp(3), []byte(data(3)), 0660)
+	bb := &bytes.Buffer{}
+	gw := gzip.NewWriter(bb)
+	gw.Write([]byte(data(3)))
+	gw.Close()
+	ioutil.WriteFile(sp(3)+".gz", bb.Bytes(), 0660)
+
+	// 4: stale temporary compressing file
+	ioutil.WriteFile(sp(4), []byte(data(4)), 0660)
+	ioutil.WriteFile(sp(4)+".gz.tmp", []byte{0x1f}, 0660)
+
+	tm := time.Now().Add(-fileLockTimeout * 2)
+	for i := 1; i <= 4; i++ {
+		os.Chtimes(sp(i), tm, tm)
+		os.Chtimes(sp(i)+".gz.tmp", tm, tm)
+	}
+
+	lg := NewLog()
+	lg.SetFormatter(TextFmtSimple)
+	lg.SetWriter(&FileWriter{Path: path, Gzip: true})
+	lg.Info("hello")
+
+	// let the file worker finish recoverCompressedFiles
+	time.Sleep(time.Millisecond * 200)
+	lg.Close()
+
+	for i := 1; i <= 4; i++ {
+		if err := iox.FileExists(sp(i)); err == nil {
+			t.Errorf("TestFileRecoverCompressedFiles file %q exists", sp(i))
+		}
+		if err := iox.FileExists(sp(i) + ".gz.tmp"); err == nil {
+			t.Errorf("TestFileRecoverCompressedFiles file %q exists", sp(i)+".gz.tmp")
+		}
+
+		bs, _ := ioutil.ReadFile(sp(i) + ".gz")
+		gr, err := gzip.NewReader(bytes.NewReader(bs))
+		if err != nil {
+			t.Errorf("TestFileRecoverCompressedFiles file %q: %v", sp(i)+".gz", err)
+			continue
+		}
+		bs, _ = ioutil.ReadAll(gr)
+
+		e := data(i)
+		a := string(bs)
+		if a != e {
+			t.Errorf("TestFileRecoverCompressedFiles\nexpect: %q, actual %q", e, a)
+		}
+	}
+}
+
+func TestFileBuffered(t *testing.T) {
+	path := "TestFileBuffered/filetest.log"
+	dir := filepath.Dir(path)
+	os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	log := NewLog()
+	log.SetFormatter(TextFmtSimple)
+	log.SetWriter(&FileWriter{Path: path, BufferSize: 1024, SyncLevel: LevelError})
+
+	// buffered
+	log.Info("hello")
+	bs, _ := ioutil.ReadFile(path)
+	if len(bs) != 0 {
+		t.Errorf("TestFileBuffered\nexpect: %q, actual %q", "", string(bs))
+	}
+
+	// flush by Flush()
+	log.Flush()
+	bs, _ = ioutil.ReadFile(path)
+	e := `[I] hello` + eol
+	if string(bs) != e {
+		t.Errorf("TestFileBuffered\nexpect: %q, actual %q", e, string(bs))
+	}
+
+	// flush by SyncLevel
+	log.Info("info")
+	log.Error("error")
+	bs, _ = ioutil.ReadFile(path)
+	e += `[I] info` + eol + `[E] error` + eol
+	if string(bs) != e {
+		t.Errorf("TestFileBuffered\nexpect: %q, actual %q", e, string(bs))
+	}
+
+	// flush by Close()
+	log.Info("close")
+	log.Close()
+	bs, _ = ioutil.ReadFile(path)
+	e += `[I] close` + eol
+	if string(bs) != e {
+		t.Errorf("TestFileBuffered\nexpect: %q, actual %q", e, string(bs))
+	}
+}
+
+func TestFileBufferedWholeRecord(t *testing.T) {
+	path := "TestFileBufferedWholeRecord/filetest.log"
+	dir := filepath.Dir(path)
+	os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	log := NewLog()
+	log.SetFormatter(TextFmtSimple)
+	log.SetWriter(&FileWriter{Path: path, BufferSize: 16, Shared: true})
+
+	// the buffered record is flushed before the record which does not fit the buffer
+	log.Info("hello")
+	log.Info("hello world")
+	bs, _ := ioutil.ReadFile(path)
+	e := `[I] hello` + eol
+	if string(bs) != e {
+		t.Errorf("TestFileBufferedWholeRecord\nexpect: %q, actual %q", e, string(bs))
+	}
+
+	// the record larger than the buffer is written directly
+	log.Info("hello world, hello world")
+	bs, _ = ioutil.ReadFile(path)
+	e += `[I] hello world` + eol + `[I] hello world, hello world` + eol
+	if string(bs) != e {
+		t.Errorf("TestFileBufferedWholeRecord\nexpect: %q, actual %q", e, string(bs))
+	}
+	log.Close()
+}
+
+func TestFileLock(t *testing.T) {
+	lp := filepath.Join(t.TempDir(), "filetest.log.lock")
+
+	// existing lock file which is not locked (left by a crashed process)
+	ioutil.WriteFile(lp, []byte{}, 0660)
+	tm := time.Now().Add(-fileLockTimeout * 2)
+	os.Chtimes(lp, tm, tm)
+
+	f1, err := lockFile(lp, 0660)
+	if err != nil {
+		t.Fatalf("TestFileLock lockFile(): %v", err)
+	}
+
+	if _, err := lockFile(lp, 0660); err != errFileLocked {
+		t.Errorf("TestFileLock lockFile() = %v, want %v", err, errFileLocked)
+	}
+
+	if err := unlockFile(f1); err != nil {
+		t.Errorf("TestFileLock unlockFile(): %v", err)
+	}
+	if err := iox.FileExists(lp); err == nil {
+		t.Errorf("TestFileLock lock file %q exists", lp)
+	}
+
+	f2, err := lockFile(lp, 0660)
+	if err != nil {
+		t.Fatalf("TestFileLock lockFile(): %v", err)
+	}
+	unlockFile(f2)
+}
+
+func TestFileSharedLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "filetest.log")
+
+	// locked by other process
+	lf, err := lockFile(path+".lock", 0660)
+	if err != nil {
+		t.Fatalf("TestFileSharedLocked lockFile(): %v", err)
+	}
+
+	lg := NewLog()
+	lg.SetFormatter(TextFmtSimple)
+	lg.SetWriter(&FileWriter{Path: path, MaxSize: 10, Shared: true})
+
+	// the rotation is skipped without waiting for the lock
+	start := time.Now()
+	for i := 1; i <= 3; i++ {
+		lg.Info("hello test ", i)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("TestFileSharedLocked write blocked %v", d)
+	}
+	if err := iox.FileExists(strings.ReplaceAll(path, ".log", "-001.log")); err == nil {
+		t.Errorf("TestFileSharedLocked rotated while locked")
+	}
+
+	// rotated at the next write after unlocked
+	unlockFile(lf)
+	lg.Info("hello test 4")
+	lg.Close()
+
+	if err := iox.FileExists(strings.ReplaceAll(path, ".log", "-001.log")); err != nil {
+		t.Errorf("TestFileSharedLocked not rotated: %v", err)
+	}
+}
+
+func TestFileBufferedFlushInterval(t *testing.T) {
+	path := "TestFileBufferedFlushInterval/filetest.log"
+	dir := filepath.Dir(path)
+	os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	log := NewLog()
+	log.SetFormatter(TextFmtSimple)
+	log.SetWriter(&FileWriter{Path: path, BufferSize: 1024, FlushInterval: time.Millisecond * 50})
+	log.Info("hello")
+
+	time.Sleep(time.Millisecond * 200)
+
+	bs, _ := ioutil.ReadFile(path)
+	e := `[I] hello` + eol
+	if string(bs) != e {
+		t.Errorf("TestFileBufferedFlushInterval\nexpect: %q, actual %q", e, string(bs))
+	}
+	log.Close()
+}
+
+func TestFileBufferedRotate(t *testing.T) {
+	path := "TestFileBufferedRotate/filetest.log"
+	dir := filepath.Dir(path)
+	os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	log := NewLog()
+	log.SetFormatter(TextFmtSimple)
+	log.SetWriter(&FileWriter{Path: path, MaxSize: 10, BufferSize: 1024})
+	for i := 1; i < 10; i++ {
+		log.Info("hello test ", i)
+	}
+	log.Close()
+
+	for i := 1; i < 9; i++ {
+		sp := strings.ReplaceAll(path, ".log", fmt.Sprintf("-%03d.log", i))
+		bs, _ := ioutil.ReadFile(sp)
+		e := fmt.Sprintf(`[I] hello test %d%s`, i, eol)
+		a := string(bs)
+		if a != e {
+			t.Errorf("TestFileBufferedRotate\nexpect: %q, actual %q", e, a)
+		}
+	}
+
+	bs, _ := ioutil.ReadFile(path)
+	e := fmt.Sprintf(`[I] hello test %d%s`, 9, eol)
+	a := string(bs)
+	if a != e {
+		t.Errorf("TestFileBufferedRotate\nexpect: %q, actual %q", e, a)
+	}
+}
+
+func TestFileRotateCompress(t *testing.T) {
+	for _, name := range []string{"gzip", "zlib", "lzw"} {
+		path := "TestFileRotateCompress/filetest.log"
+		dir := filepath.Dir(path)
+		os.RemoveAll(dir)
+
+		fw := &FileWriter{Path: path, MaxSize: 10}
+		if err := fw.SetCompress(name); err != nil {
+			t.Fatal(err)
+		}
+
+		c := GetCompressor(name)
+
+		log := NewLog()
+		log.SetFormatter(TextFmtSimple)
+		log.SetWriter(fw)
+		for i := 1; i < 10; i++ {
+			log.Info("hello test ", i)
+		}
+		log.Close()
+
+		for i := 1; i < 9; i++ {
+			sp := strings.ReplaceAll(path, ".log", fmt.Sprintf("-%03d.log", i))
+			if err := iox.FileExists(sp); err == nil {
+				t.Errorf("TestFileRotateCompress(%s) file %q exists", name, sp)
+			}
+
+			bs, _ := ioutil.ReadFile(sp + c.Ext())
+			r, _ := c.NewReader(bytes.NewReader(bs))
+			bs, _ = ioutil.ReadAll(r)
+
+			e := fmt.Sprintf(`[I] hello test %d%s`, i, eol)
+			a := string(bs)
+			if a != e {
+				t.Errorf("TestFileRotateCompress(%s)\nexpect: %q, actual %q", name, e, a)
+			}
+		}
+	}
+	os.RemoveAll("TestFileRotateCompress")
+}
+
+func TestFileRotateCompressDelay(t *testing.T) {
+	path := "TestFileRotateCompressDelay/filetest.log"
+	dir := filepath.Dir(path)
+	os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+
+	fw := &FileWriter{Path: path, MaxSize: 10, Gzip: true}
+	if err := fw.SetCompressDelay("1h"); err != nil {
+		t.Fatal(err)
+	}
+
+	log := NewLog()
+	log.SetFormatter(TextFmtSimple)
+	log.SetWriter(fw)
+	for i := 1; i < 4; i++ {
+		log.Info("hello test ", i)
+	}
+
+	// the rotated files are not compressed in the delay
+	time.Sleep(time.Millisecond * 100)
+	for i := 1; i < 3; i++ {
+		sp := strings.ReplaceAll(path, ".log", fmt.Sprintf("-%03d.log", i))
+		if err := iox.FileExists(sp); err != nil {
+			t.Errorf("TestFileRotateCompressDelay file %q not exists: %v", sp, err)
+		}
+	}
+
+	// the rotated files in the delay are not compressed on close
+	start := time.Now()
+	log.Close()
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("TestFileRotateCompressDelay Close() takes %v", d)
+	}
+	for i := 1; i < 3; i++ {
+		sp := strings.ReplaceAll(path, ".log", fmt.Sprintf("-%03d.log", i))
+		if err := iox.FileExists(sp); err != nil {
+			t.Errorf("TestFileRotateCompressDelay file %q not exists: %v", sp, err)
+		}
+	}
+
+	// the rotated files are compressed at the next startup after the delay
+	log.SetWriter(&FileWriter{Path: path, Gzip: true})
+	log.Info("hello test")
+	log.Close()
+	for i := 1; i < 3; i++ {
+		sp := strings.ReplaceAll(path, ".log", fmt.Sprintf("-%03d.log", i))
+		if err := iox.FileExists(sp); err == nil {
+			t.Errorf("TestFileRotateCompressDelay file %q exists", sp)
+		}
+		if err := iox.FileExists(sp + ".gz"); err != nil {
+			t.Errorf("TestFileRotateCompressDelay file %q not exists: %v", sp+".gz", err)
+		}
+	}
+}
+
+func TestFileCompressKeepModTime(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "filetest-001.log")
+	ioutil.WriteFile(src, []byte("hello"), 0660)
+	tm := time.Now().Add(-time.Hour).Truncate(time.Second)
+	os.Chtimes(src, tm, tm)
+
+	fw := &FileWriter{Path: filepath.Join(dir, "filetest.log"), FilePerm: 0660}
+	fw.compressFile(GetCompressor("gzip"), src)
+
+	if err := iox.FileExists(src); err == nil {
+		t.Errorf("TestFileCompressKeepModTime file %q exists", src)
+	}
+
+	fi, err := os.Stat(src + ".gz")
+	if err != nil {
+		t.Fatalf("TestFileCompressKeepModTime file %q not exists: %v", src+".gz", err)
+	}
+	if !fi.ModTime().Equal(tm) {
+		t.Errorf("TestFileCompressKeepModTime modified time = %v, want %v", fi.ModTime(), tm)
+	}
+}
+
+func TestFileConfigCompress(t *testing.T) {
+	fw := CreateWriter("file").(*FileWriter)
+	err := ConfigWriter(fw, map[string]interface{}{
+		"compress":      "zlib",
+		"compressDelay": "10m",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fw.Compress != "zlib" || fw.CompressDelay != time.Minute*10 {
+		t.Errorf("TestFileConfigCompress invalid config: %q, %v", fw.Compress, fw.CompressDelay)
+	}
+
+	err = ConfigWriter(fw, map[string]interface{}{
+		"compress": "unknown",
+	})
+	if err == nil {
+		t.Error("TestFileConfigCompress expect error for unknown compressor")
+	}
+}
+
+func TestIsRotatedName(t *testing.T) {
+	cs := []struct {
+		p string
+		n string
+		w bool
+	}{
+		{"logs/app.log", "app-001.log", true},
+		{"logs/app.log", "app-1234.log.gz", true},
+		{"logs/app.log", "app-20060102.log", true},
+		{"logs/app.log", "app-2006010215.log.zz", true},
+		{"logs/app.log", "app-20060102-002.log", true},
+		{"logs/app", "app-002.log", true},
+		{"logs/app.log", "app.log", false},
+		{"logs/app.log", "app-access.log", false},
+		{"logs/app.log", "app-01.log", false},
+		{"logs/app.log", "app-001.txt", false},
+		{"logs/app.log", "app-20060102-access.log", false},
+		{"logs/app.log", "application-001.log", false},
+	}
+
+	for i, c := range cs {
+		if a := IsRotatedName(c.p, c.n); a != c.w {
+			t.Errorf("[%d] IsRotatedName(%q, %q) = %v, want %v", i, c.p, c.n, a, c.w)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// GELFFormatter GELF (Graylog Extended Log Format) 1.1 formatter
+// The logger properties and the structured fields are written as the additional fields ("_" + key).
+// The caller info are written as the additional fields "_logger", "_file", "_line", "_func".
+type GELFFormatter struct {
+	Host      string // host name, default: os.Hostname()
+	Delimiter string // message delimiter, default: EOL (use "\x00" for the GELF TCP input)
+}
+
+// NewGELFFormatter create a GELF Formatter instance
+func NewGELFFormatter(host string) *GELFFormatter {
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	return &GELFFormatter{Host: host, Delimiter: eol}
+}
+
+// Write format the log event as a GELF json to the buffer 'bb'
+func (gf *GELFFormatter) Write(bb *bytes.Buffer, le *Event) {
+	m := map[string]interface{}{}
+
+	name := ""
+	if le.Logger != nil {
+		name = le.Logger.GetName()
+		gelfAddFields(m, le.Logger.GetProps())
+	}
+	gelfAddFields(m, le.Fields)
+
+	msg := le.Msg
+	if i := strings.IndexAny(msg, "\r\n"); i >= 0 {
+		msg = msg[:i]
+	}
+
+	m["version"] = "1.1"
+	m["host"] = gf.Host
+	m["short_message"] = msg
+	if msg != le.Msg || le.Trace != "" {
+		m["full_message"] = le.Msg + eol + le.Trace
+	}
+	m["timestamp"] = float64(le.When.UnixNano()/int64(1000000)) / 1000
+	m["level"] = syslogSeverity(le.Level)
+	m["_logger"] = name
+	if le.File != "" {
+		m["_file"] = le.File
+		m["_line"] = le.Line
+		m["_func"] = le.Func
+	}
+
+	b, _ := json.Marshal(m)
+	bb.Write(b)
+	bb.WriteString(gf.Delimiter)
+}
+
+// Format format the log event to a GELF json string
+func (gf *GELFFormatter) Format(le *Event) string {
+	bb := &bytes.Buffer{}
+	gf.Write(bb, le)
+	return bb.String()
+}
+
+// gelfAddFields add the fields 'fs' as the GELF additional fields to 'm'
+func gelfAddFields(m map[string]interface{}, fs map[string]interface{}) {
+	for k, v := range fs {
+		// the additional field name must match ^[\w\.\-]*$, and "_id" is reserved
+		k = strings.Map(func(c rune) rune {
+			if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == '.' || c == '-' {
+				return c
+			}
+			return '_'
+		}, k)
+		if k == "id" {
+			k = "_id"
+		}
+		m["_"+k] = v
+	}
+}
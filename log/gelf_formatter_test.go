@@ -0,0 +1,49 @@
+package log
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGELFFormat(t *testing.T) {
+	gf := NewLogFormatter("gelf:myhost")
+	lg := NewLog().GetLogger("app")
+	lg.SetProp("env", "prod")
+	le := newEvent(lg.With("id", 1, "user name", "a"), LevelError, "line1\nline2")
+	le.When = time.Unix(1600000000, 123000000)
+	le.Caller(2, true)
+
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(gf.Format(le)), &m))
+
+	assert.Equal(t, "1.1", m["version"])
+	assert.Equal(t, "myhost", m["host"])
+	assert.Equal(t, "line1", m["short_message"])
+	assert.Equal(t, "line1\nline2"+eol+le.Trace, m["full_message"])
+	assert.Equal(t, 1600000000.123, m["timestamp"])
+	assert.Equal(t, float64(3), m["level"])
+	assert.Equal(t, "app", m["_logger"])
+	assert.Equal(t, "gelf_formatter_test.go", m["_file"])
+	assert.Equal(t, float64(le.Line), m["_line"])
+	assert.Equal(t, "log.TestGELFFormat", m["_func"])
+	assert.Equal(t, "prod", m["_env"])
+	assert.Equal(t, float64(1), m["__id"])
+	assert.Equal(t, "a", m["_user_name"])
+}
+
+func TestGELFFormatShort(t *testing.T) {
+	gf := &GELFFormatter{Host: "h", Delimiter: "\x00"}
+	le := newEvent(NewLog().GetLogger(""), LevelInfo, "msg")
+	le.When = time.Unix(1600000000, 0)
+	le.File = ""
+	assert.Equal(t, `{"_logger":"","host":"h","level":6,"short_message":"msg","timestamp":1600000000,"version":"1.1"}`+"\x00", gf.Format(le))
+}
+
+func TestGELFFormatNoLogger(t *testing.T) {
+	gf := &GELFFormatter{Host: "h", Delimiter: "\x00"}
+	le := &Event{Level: LevelInfo, Msg: "msg", When: time.Unix(1600000000, 0)}
+	assert.Equal(t, `{"_logger":"","host":"h","level":6,"short_message":"msg","timestamp":1600000000,"version":"1.1"}`+"\x00", gf.Format(le))
+}
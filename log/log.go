@@ -19,10 +19,15 @@
 //	log := log.GetLogger("foo")
 //	log.Debug("hello")
 //
+// A Logger with structured fields:
+//	log := log.GetLogger("foo").With("user", uid, "req", rid)
+//	log.Info("hello")
+//
 package log
 
 import (
 	"io"
+	"os"
 )
 
 //--------------------------------------------------------------------
@@ -137,11 +142,52 @@ func SetProps(props map[string]interface{}) {
 	_log.SetProps(props)
 }
 
+// With returns a child logger with the key/value pairs 'kvs' added to the structured fields
+func With(kvs ...interface{}) Logger {
+	return _log.With(kvs...)
+}
+
 // Close will remove all writers and stop async goroutine
 func Close() {
 	_log.Close()
 }
 
+// SetFatalHandler set the fatal handler which is called after a FATAL event is logged
+func SetFatalHandler(fh FatalHandler) {
+	_log.SetFatalHandler(fh)
+}
+
+// GetMetrics get the metrics of the default log
+func GetMetrics() *Metrics {
+	return _log.GetMetrics()
+}
+
+// SetHooks set the hooks which are called for each event before the event is written
+func SetHooks(hs ...Hook) {
+	_log.SetHooks(hs...)
+}
+
+// AddHook add the hook which is called for each event before the event is written
+func AddHook(h Hook) {
+	_log.AddHook(h)
+}
+
+// Recover recover the panic and log it as a ERROR event with the stack trace of the panic.
+// The log is flushed, and the panic is re-panicked if 'repanic' is true.
+// It must be called by defer directly: defer log.Recover()
+func Recover(repanic ...bool) {
+	if r := recover(); r != nil {
+		_log.panic(r, len(repanic) > 0 && repanic[0])
+	}
+}
+
+// CloseOnSignal close the log when one of the signals 'sigs' (default: SIGINT, SIGTERM) is received,
+// and then exit the process with the code 128 + signal number.
+// It returns a function to stop handling the signals.
+func CloseOnSignal(sigs ...os.Signal) func() {
+	return _log.CloseOnSignal(sigs...)
+}
+
 // IsFatalEnabled is FATAL level enabled
 func IsFatalEnabled() bool {
 	return _log.logger.IsFatalEnabled()
@@ -5,6 +5,8 @@ import (
 	"path"
 	"runtime"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func skipTest(t *testing.T, msg string) {
@@ -131,3 +133,15 @@ func TestLogPackage(t *testing.T) {
 		Trace("hello", "trace")
 	}
 }
+
+func TestLogWith(t *testing.T) {
+	log1 := NewLog()
+	log2 := log1.GetLogger("hello")
+	log3 := log2.With("a", 1, "b", "2")
+	log4 := log3.With("a", "x")
+
+	assert.Nil(t, log2.GetFields())
+	assert.Equal(t, "hello", log3.GetName())
+	assert.Equal(t, map[string]interface{}{"a": 1, "b": "2"}, log3.GetFields())
+	assert.Equal(t, map[string]interface{}{"a": "x", "b": "2"}, log4.GetFields())
+}
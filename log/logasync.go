@@ -0,0 +1,129 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// Overflow the overflow policy of the async log when the event queue is full
+type Overflow uint32
+
+// Overflow policy
+const (
+	OverflowBlock      Overflow = iota // block until the event queue is available
+	OverflowDropNewest                 // drop the new event
+	OverflowDropOldest                 // drop the oldest event in the event queue
+	OverflowDropBelow                  // drop the new event if it's level is lower than the overflow level, otherwise block
+)
+
+// String return overflow policy string
+func (ov Overflow) String() string {
+	switch ov {
+	case OverflowDropNewest:
+		return "drop-newest"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowDropBelow:
+		return "drop-below"
+	default:
+		return "block"
+	}
+}
+
+// ParseOverflow parse overflow policy from string
+// block: OverflowBlock
+// drop, drop-newest: OverflowDropNewest
+// drop-oldest: OverflowDropOldest
+// drop-below: OverflowDropBelow
+func ParseOverflow(s string) (Overflow, error) {
+	switch strings.ToLower(s) {
+	case "", "block":
+		return OverflowBlock, nil
+	case "drop", "drop-newest":
+		return OverflowDropNewest, nil
+	case "drop-oldest":
+		return OverflowDropOldest, nil
+	case "drop-below":
+		return OverflowDropBelow, nil
+	default:
+		return OverflowBlock, fmt.Errorf("Invalid overflow policy %q", s)
+	}
+}
+
+// GetOverflow return the overflow policy of the async log
+func (log *Log) GetOverflow() Overflow {
+	return Overflow(atomic.LoadUint32((*uint32)(&log.overflow)))
+}
+
+// SetOverflow set the overflow policy of the async log
+func (log *Log) SetOverflow(ov Overflow) {
+	atomic.StoreUint32((*uint32)(&log.overflow), uint32(ov))
+}
+
+// GetOverflowLevel return the overflow level of the OverflowDropBelow policy
+func (log *Log) GetOverflowLevel() Level {
+	return Level(atomic.LoadUint32((*uint32)(&log.ovlevel)))
+}
+
+// SetOverflowLevel set the overflow level of the OverflowDropBelow policy.
+// the events which level is lower than the overflow level will be dropped when the event queue is full.
+func (log *Log) SetOverflowLevel(lvl Level) {
+	atomic.StoreUint32((*uint32)(&log.ovlevel), uint32(lvl))
+}
+
+// GetDroppedCount return the count of the events dropped by the overflow policy
+func (log *Log) GetDroppedCount() uint64 {
+	return atomic.LoadUint64(&log.dropped)
+}
+
+// enqueue put the event to the event queue by the overflow policy
+func (log *Log) enqueue(le *Event) {
+	enqueueEvent(log.evtChan, le, log.GetOverflow(), log.GetOverflowLevel(), log.drop)
+}
+
+// drop drop the event and count it
+func (log *Log) drop(le *Event) {
+	atomic.AddUint64(&log.dropped, 1)
+	putEvent(le)
+}
+
+// enqueueEvent put the event to the event queue 'ec' by the overflow policy 'ov',
+// the dropped events are passed to the function 'drop'.
+func enqueueEvent(ec chan *Event, le *Event, ov Overflow, ovl Level, drop func(*Event)) {
+	switch ov {
+	case OverflowDropNewest:
+		offerEvent(ec, le, drop)
+	case OverflowDropOldest:
+		for {
+			select {
+			case ec <- le:
+				return
+			default:
+			}
+
+			select {
+			case oe := <-ec:
+				drop(oe)
+			default:
+			}
+		}
+	case OverflowDropBelow:
+		if le.Level > ovl {
+			offerEvent(ec, le, drop)
+			return
+		}
+		ec <- le
+	default:
+		ec <- le
+	}
+}
+
+// offerEvent put the event to the event queue 'ec', drop it if the event queue is full
+func offerEvent(ec chan *Event, le *Event, drop func(*Event)) {
+	select {
+	case ec <- le:
+	default:
+		drop(le)
+	}
+}
@@ -0,0 +1,133 @@
+package log
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testBlockWriter a writer blocks until released
+type testBlockWriter struct {
+	started chan struct{}
+	release chan struct{}
+	msgs    []string
+}
+
+func newTestBlockWriter() *testBlockWriter {
+	return &testBlockWriter{
+		started: make(chan struct{}, 100),
+		release: make(chan struct{}),
+	}
+}
+
+func (bw *testBlockWriter) Write(le *Event) {
+	bw.started <- struct{}{}
+	<-bw.release
+	bw.msgs = append(bw.msgs, le.Msg)
+}
+
+func (bw *testBlockWriter) Flush() {
+}
+
+func (bw *testBlockWriter) Close() {
+}
+
+func testAsyncOverflow(t *testing.T, ov Overflow, exp []string, dropped uint64) {
+	bw := newTestBlockWriter()
+
+	log := NewLog()
+	log.SetWriter(bw)
+	log.SetOverflow(ov)
+	log.SetOverflowLevel(LevelWarn)
+	log.Async(2)
+
+	log.Info("1")
+	<-bw.started // "1" is writing, the queue is empty
+
+	log.Info("2")
+	log.Info("3")
+	log.Info("4") // queue is full
+	log.Error("5")
+
+	close(bw.release)
+	log.Close()
+
+	assert.Equal(t, exp, bw.msgs)
+	assert.Equal(t, dropped, log.GetDroppedCount())
+}
+
+func TestAsyncOverflowDropNewest(t *testing.T) {
+	testAsyncOverflow(t, OverflowDropNewest, []string{"1", "2", "3"}, 2)
+}
+
+func TestAsyncOverflowDropOldest(t *testing.T) {
+	testAsyncOverflow(t, OverflowDropOldest, []string{"1", "4", "5"}, 2)
+}
+
+func TestAsyncOverflowDropBelow(t *testing.T) {
+	bw := newTestBlockWriter()
+
+	log := NewLog()
+	log.SetWriter(bw)
+	log.SetOverflow(OverflowDropBelow)
+	log.SetOverflowLevel(LevelWarn)
+	log.Async(1)
+
+	log.Info("1")
+	<-bw.started // "1" is writing, the queue is empty
+
+	log.Info("2")
+	log.Info("3") // queue is full, dropped
+	go close(bw.release)
+	log.Error("4") // block until "2" is taken from the queue
+	log.Close()
+
+	assert.Equal(t, []string{"1", "2", "4"}, bw.msgs)
+	assert.Equal(t, uint64(1), log.GetDroppedCount())
+}
+
+func TestParseOverflow(t *testing.T) {
+	cs := []struct {
+		s string
+		w Overflow
+	}{
+		{"", OverflowBlock},
+		{"block", OverflowBlock},
+		{"drop", OverflowDropNewest},
+		{"Drop-Newest", OverflowDropNewest},
+		{"drop-oldest", OverflowDropOldest},
+		{"drop-below", OverflowDropBelow},
+	}
+
+	for i, c := range cs {
+		a, err := ParseOverflow(c.s)
+		assert.Nil(t, err, "[%d] ParseOverflow(%q)", i, c.s)
+		assert.Equal(t, c.w, a, "[%d] ParseOverflow(%q)", i, c.s)
+	}
+
+	_, err := ParseOverflow("unknown")
+	assert.NotNil(t, err)
+}
+
+func TestAsyncOverflowConcurrent(t *testing.T) {
+	log := NewLog()
+	log.SetWriter(&testBatchWriter{})
+	log.Async(10)
+	defer log.Close()
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			log.SetOverflow(Overflow(i % 3))
+			log.SetOverflowLevel(LevelWarn)
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		log.Info(i)
+	}
+	wg.Wait()
+}
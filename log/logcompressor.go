@@ -0,0 +1,153 @@
+package log
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// Compressor log file compressor interface
+type Compressor interface {
+	// Ext return the file extension of the compressed file (e.g. ".gz")
+	Ext() string
+
+	// NewWriter create a compress writer
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+
+	// NewReader create a decompress reader
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// GzipCompressor gzip compressor
+type GzipCompressor struct {
+	Level int // compression level, 0: default
+}
+
+// Ext return ".gz"
+func (gc *GzipCompressor) Ext() string {
+	return ".gz"
+}
+
+// NewWriter create a gzip writer
+func (gc *GzipCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	if gc.Level == 0 {
+		return gzip.NewWriter(w), nil
+	}
+	return gzip.NewWriterLevel(w, gc.Level)
+}
+
+// NewReader create a gzip reader
+func (gc *GzipCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// ZlibCompressor zlib compressor
+type ZlibCompressor struct {
+	Level int // compression level, 0: default
+}
+
+// Ext return ".zz"
+func (zc *ZlibCompressor) Ext() string {
+	return ".zz"
+}
+
+// NewWriter create a zlib writer
+func (zc *ZlibCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	if zc.Level == 0 {
+		return zlib.NewWriter(w), nil
+	}
+	return zlib.NewWriterLevel(w, zc.Level)
+}
+
+// NewReader create a zlib reader
+func (zc *ZlibCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
+
+// LzwCompressor Unix compress (.Z) compressor (LZW, fast, low compression ratio).
+// The compressed file can be decompressed by "uncompress" or "gzip -d".
+type LzwCompressor struct {
+}
+
+// Ext return ".Z"
+func (lc *LzwCompressor) Ext() string {
+	return ".Z"
+}
+
+// NewWriter create a .Z compress writer
+func (lc *LzwCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return newLzwWriter(w), nil
+}
+
+// NewReader create a .Z decompress reader
+func (lc *LzwCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return newLzwReader(r)
+}
+
+type namedCompressor struct {
+	name string
+	comp Compressor
+}
+
+// compressors the registered compressors in registration order
+var compressors []namedCompressor
+
+// RegisterCompressor register log file compressor
+func RegisterCompressor(name string, c Compressor) {
+	for i, nc := range compressors {
+		if nc.name == name {
+			compressors[i].comp = c
+			return
+		}
+	}
+	compressors = append(compressors, namedCompressor{name, c})
+}
+
+// GetCompressor get a registered compressor by name, return nil if not found
+func GetCompressor(name string) Compressor {
+	for _, nc := range compressors {
+		if nc.name == name {
+			return nc.comp
+		}
+	}
+	return nil
+}
+
+// GetCompressorByExt find a registered compressor by the file extension of the path, return nil if not found.
+// If the extensions of several compressors match, the longest one (or the first registered one) is returned.
+func GetCompressorByExt(path string) (c Compressor) {
+	n := 0
+	for _, nc := range compressors {
+		ext := nc.comp.Ext()
+		if len(ext) > n && len(path) > len(ext) && path[len(path)-len(ext):] == ext {
+			c, n = nc.comp, len(ext)
+		}
+	}
+	return
+}
+
+// checkCompressedFile read the compressed file completely to check it's integrity
+func checkCompressedFile(c Compressor, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := c.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	_, err = io.Copy(ioutil.Discard, r)
+	return err
+}
+
+func init() {
+	RegisterCompressor("gzip", &GzipCompressor{})
+	RegisterCompressor("zlib", &ZlibCompressor{})
+	RegisterCompressor("lzw", &LzwCompressor{})
+}
@@ -0,0 +1,345 @@
+package log
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// The LZW codec of the Unix compress (.Z) file format (block mode).
+// The codes are packed LSB first, in groups of 8 codes which are padded when the code width changes.
+
+const (
+	lzwMagic1    = 0x1f
+	lzwMagic2    = 0x9d
+	lzwBlockMode = 0x80
+	lzwBitsMask  = 0x1f
+	lzwInitBits  = 9
+	lzwMaxBits   = 16
+	lzwClear     = 256
+	lzwFirst     = 257
+)
+
+var errLzwCorrupt = errors.New("lzw: corrupt input")
+
+// lzwWriter the .Z compress writer
+type lzwWriter struct {
+	w       io.Writer
+	err     error
+	buf     []byte
+	bits    uint64 // the bit buffer
+	nb      uint   // the number of the bits in the bit buffer
+	maxBits int
+	nbits   int // the current code width
+	ncodes  int // the number of the codes written with the current code width
+	freeEnt int
+	ent     int // the current prefix code, -1: none
+	dict    map[uint32]int
+}
+
+func newLzwWriter(w io.Writer) *lzwWriter {
+	zw := &lzwWriter{
+		w:       w,
+		buf:     make([]byte, 0, 4096),
+		maxBits: lzwMaxBits,
+		ent:     -1,
+	}
+	zw.buf = append(zw.buf, lzwMagic1, lzwMagic2, byte(lzwBlockMode|lzwMaxBits))
+	zw.reset()
+	return zw
+}
+
+func (zw *lzwWriter) reset() {
+	zw.nbits = lzwInitBits
+	zw.ncodes = 0
+	zw.freeEnt = lzwFirst
+	zw.dict = make(map[uint32]int)
+}
+
+func (zw *lzwWriter) putCode(code int) {
+	zw.bits |= uint64(code) << zw.nb
+	zw.nb += uint(zw.nbits)
+	for zw.nb >= 8 {
+		zw.buf = append(zw.buf, byte(zw.bits))
+		zw.bits >>= 8
+		zw.nb -= 8
+	}
+	zw.ncodes++
+}
+
+// pad fill the current group of 8 codes
+func (zw *lzwWriter) pad() {
+	for zw.ncodes%8 != 0 {
+		zw.putCode(0)
+	}
+	zw.ncodes = 0
+}
+
+func (zw *lzwWriter) output(code int) {
+	zw.putCode(code)
+
+	if len(zw.buf) >= cap(zw.buf)-8 {
+		zw.flush()
+	}
+}
+
+func (zw *lzwWriter) flush() {
+	if zw.err == nil && len(zw.buf) > 0 {
+		_, zw.err = zw.w.Write(zw.buf)
+	}
+	zw.buf = zw.buf[:0]
+}
+
+// Write compress p
+func (zw *lzwWriter) Write(p []byte) (int, error) {
+	if zw.err != nil {
+		return 0, zw.err
+	}
+
+	for _, c := range p {
+		if zw.ent < 0 {
+			zw.ent = int(c)
+			continue
+		}
+
+		key := uint32(zw.ent)<<8 | uint32(c)
+		if code, ok := zw.dict[key]; ok {
+			zw.ent = code
+			continue
+		}
+
+		zw.output(zw.ent)
+		zw.ent = int(c)
+
+		if zw.freeEnt < 1<<zw.maxBits {
+			zw.dict[key] = zw.freeEnt
+			zw.freeEnt++
+		}
+
+		if zw.freeEnt >= 1<<zw.maxBits {
+			// the table is full, start a new one
+			zw.output(lzwClear)
+			zw.pad()
+			zw.reset()
+		} else if zw.freeEnt > 1<<zw.nbits {
+			zw.pad()
+			zw.nbits++
+		}
+	}
+
+	if zw.err != nil {
+		return 0, zw.err
+	}
+	return len(p), nil
+}
+
+// Close write the last code and flush the buffer
+func (zw *lzwWriter) Close() error {
+	if zw.ent >= 0 {
+		zw.output(zw.ent)
+		zw.ent = -1
+	}
+	if zw.nb > 0 {
+		zw.buf = append(zw.buf, byte(zw.bits))
+		zw.bits, zw.nb = 0, 0
+	}
+	zw.flush()
+	return zw.err
+}
+
+// lzwReader the .Z decompress reader
+type lzwReader struct {
+	r       io.ByteReader
+	err     error
+	bits    uint64
+	nb      uint
+	maxBits int
+	block   bool
+	nbits   int
+	maxCode int
+	ncodes  int
+	freeEnt int
+	oldCode int
+	finChar byte
+	prefix  []uint16
+	suffix  []byte
+	stack   []byte
+	out     []byte // the decoded bytes (in the stack buffer) to read
+}
+
+func newLzwReader(r io.Reader) (*lzwReader, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	var hdr [3]byte
+	for i := range hdr {
+		c, err := br.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		hdr[i] = c
+	}
+	if hdr[0] != lzwMagic1 || hdr[1] != lzwMagic2 {
+		return nil, errors.New("lzw: invalid header")
+	}
+
+	maxBits := int(hdr[2] & lzwBitsMask)
+	if maxBits < lzwInitBits || maxBits > lzwMaxBits {
+		return nil, errors.New("lzw: unsupported max bits")
+	}
+
+	zr := &lzwReader{
+		r:       br,
+		maxBits: maxBits,
+		block:   hdr[2]&lzwBlockMode != 0,
+		nbits:   lzwInitBits,
+		maxCode: 1<<lzwInitBits - 1,
+		oldCode: -1,
+		prefix:  make([]uint16, 1<<maxBits),
+		suffix:  make([]byte, 1<<maxBits),
+		stack:   make([]byte, 0, 1<<maxBits),
+	}
+	for i := 0; i < 256; i++ {
+		zr.suffix[i] = byte(i)
+	}
+	zr.freeEnt = 256
+	if zr.block {
+		zr.freeEnt = lzwFirst
+	}
+	return zr, nil
+}
+
+// getCode read a code, the incomplete code at the end of the input is ignored
+func (zr *lzwReader) getCode() (int, error) {
+	for zr.nb < uint(zr.nbits) {
+		c, err := zr.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		zr.bits |= uint64(c) << zr.nb
+		zr.nb += 8
+	}
+
+	code := int(zr.bits & (1<<uint(zr.nbits) - 1))
+	zr.bits >>= uint(zr.nbits)
+	zr.nb -= uint(zr.nbits)
+	zr.ncodes++
+	return code, nil
+}
+
+// skip skip the padding codes of the current group of 8 codes
+func (zr *lzwReader) skip() error {
+	for zr.ncodes%8 != 0 {
+		if _, err := zr.getCode(); err != nil {
+			return err
+		}
+	}
+	zr.ncodes = 0
+	return nil
+}
+
+func (zr *lzwReader) decode() error {
+	for {
+		if zr.freeEnt > zr.maxCode {
+			if err := zr.skip(); err != nil {
+				return err
+			}
+			zr.nbits++
+			if zr.nbits == zr.maxBits {
+				zr.maxCode = 1 << zr.maxBits
+			} else {
+				zr.maxCode = 1<<zr.nbits - 1
+			}
+		}
+
+		code, err := zr.getCode()
+		if err != nil {
+			return err
+		}
+
+		if zr.oldCode < 0 {
+			if code >= 256 {
+				return errLzwCorrupt
+			}
+			zr.oldCode = code
+			zr.finChar = byte(code)
+			zr.out = append(zr.stack[:0], byte(code))
+			return nil
+		}
+
+		if code == lzwClear && zr.block {
+			zr.freeEnt = lzwFirst - 1
+			if err := zr.skip(); err != nil {
+				return err
+			}
+			zr.nbits = lzwInitBits
+			zr.maxCode = 1<<lzwInitBits - 1
+			continue
+		}
+
+		incode := code
+		stack := zr.stack[:0]
+		if code >= zr.freeEnt {
+			if code > zr.freeEnt {
+				return errLzwCorrupt
+			}
+			stack = append(stack, zr.finChar)
+			code = zr.oldCode
+		}
+		for code >= 256 {
+			if len(stack) >= cap(stack) {
+				return errLzwCorrupt
+			}
+			stack = append(stack, zr.suffix[code])
+			code = int(zr.prefix[code])
+		}
+		zr.finChar = byte(code)
+		stack = append(stack, zr.finChar)
+
+		for i, j := 0, len(stack)-1; i < j; i, j = i+1, j-1 {
+			stack[i], stack[j] = stack[j], stack[i]
+		}
+		zr.out = stack
+
+		if zr.freeEnt < 1<<zr.maxBits {
+			zr.prefix[zr.freeEnt] = uint16(zr.oldCode)
+			zr.suffix[zr.freeEnt] = zr.finChar
+			zr.freeEnt++
+		}
+		zr.oldCode = incode
+		return nil
+	}
+}
+
+// Read read the decompressed data
+func (zr *lzwReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(zr.out) == 0 {
+			if zr.err != nil {
+				break
+			}
+			if zr.err = zr.decode(); zr.err != nil {
+				continue
+			}
+		}
+		c := copy(p[n:], zr.out)
+		zr.out = zr.out[c:]
+		n += c
+	}
+
+	if n > 0 {
+		return n, nil
+	}
+	return 0, zr.err
+}
+
+// Close close the reader
+func (zr *lzwReader) Close() error {
+	return nil
+}
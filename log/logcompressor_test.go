@@ -0,0 +1,83 @@
+package log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressors(t *testing.T) {
+	data := []byte("hello compressor hello compressor hello compressor")
+
+	for _, name := range []string{"gzip", "zlib", "lzw"} {
+		c := GetCompressor(name)
+		if !assert.NotNil(t, c, name) {
+			continue
+		}
+
+		bb := &bytes.Buffer{}
+		w, err := c.NewWriter(bb)
+		assert.Nil(t, err, name)
+		w.Write(data)
+		assert.Nil(t, w.Close(), name)
+
+		r, err := c.NewReader(bytes.NewReader(bb.Bytes()))
+		assert.Nil(t, err, name)
+		bs, err := ioutil.ReadAll(r)
+		assert.Nil(t, err, name)
+		assert.Equal(t, data, bs, name)
+
+		assert.Equal(t, c, GetCompressorByExt("test.log"+c.Ext()), name)
+	}
+
+	assert.Nil(t, GetCompressor("unknown"))
+	assert.Nil(t, GetCompressorByExt("test.log"))
+}
+
+func TestLzwCompressorLarge(t *testing.T) {
+	// over the code width changes and the table clears
+	data := make([]byte, 1024*1024)
+	for i := range data {
+		data[i] = byte(i*i>>7) ^ byte(i>>11)
+	}
+
+	c := &LzwCompressor{}
+	bb := &bytes.Buffer{}
+	w, _ := c.NewWriter(bb)
+	w.Write(data[:1000])
+	w.Write(data[1000:])
+	assert.Nil(t, w.Close())
+
+	r, err := c.NewReader(bytes.NewReader(bb.Bytes()))
+	if assert.Nil(t, err) {
+		bs, err := ioutil.ReadAll(r)
+		assert.Nil(t, err)
+		assert.Equal(t, data, bs)
+	}
+
+	_, err = c.NewReader(bytes.NewReader([]byte("hello")))
+	assert.NotNil(t, err)
+}
+
+type testTarGzCompressor struct {
+	GzipCompressor
+}
+
+func (tc *testTarGzCompressor) Ext() string {
+	return ".tar.gz"
+}
+
+func TestGetCompressorByExt(t *testing.T) {
+	tc := &testTarGzCompressor{}
+	RegisterCompressor("test-targz", tc)
+	defer func() {
+		compressors = compressors[:len(compressors)-1]
+	}()
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, tc, GetCompressorByExt("test.log.tar.gz"))
+		assert.Equal(t, GetCompressor("gzip"), GetCompressorByExt("test.log.gz"))
+	}
+}
@@ -14,16 +14,93 @@ import (
 	"github.com/pandafw/pango/str"
 )
 
-// Config config log by configuration file
+// logConfig the parsed log configuration
+type logConfig struct {
+	async    *int
+	overflow *Overflow
+	ovlevel  *Level
+	format   Formatter
+	fatal    *FatalHandler
+	hooks    []Hook
+	hooked   bool
+	level    Level
+	levels   map[string]Level
+	writer   Writer
+}
+
+// Config config log by configuration file.
+// The configuration file is parsed completely before it is applied,
+// so the current configuration is kept if the configuration file is invalid.
+// The absent "async" and "level" settings reset the log to sync mode and the default levels.
 func (log *Log) Config(filename string) error {
+	lc := &logConfig{}
+
+	var err error
 	ext := strings.ToLower(filepath.Ext(filename))
 	if ext == ".json" || ext == ".js" {
-		return log.configJSON(filename)
+		err = lc.loadJSON(filename)
+	} else {
+		err = lc.loadINI(filename)
+	}
+	if err != nil {
+		if lc.writer != nil {
+			lc.writer.Close()
+		}
+		return err
+	}
+
+	log.apply(lc)
+	return nil
+}
+
+// apply apply the parsed log configuration in the mutex lock.
+// The formatter and the writer are switched together (by the async goroutine after the queued events
+// are written in async mode), so an event is never written by the old writer with the new formatter.
+func (log *Log) apply(lc *logConfig) {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	if lc.overflow != nil {
+		log.SetOverflow(*lc.overflow)
+	}
+	if lc.ovlevel != nil {
+		log.SetOverflowLevel(*lc.ovlevel)
+	}
+	if lc.fatal != nil {
+		log.fatalh = *lc.fatal
+	}
+	if lc.hooked {
+		log.SetHooks(lc.hooks...)
 	}
-	return log.configINI(filename)
+
+	lvl := lc.level
+	if lvl == LevelNone {
+		lvl = LevelTrace
+	}
+	log.SetLevel(lvl)
+
+	lvls := lc.levels
+	if lvls == nil {
+		lvls = make(map[string]Level)
+	}
+	log.SetLevels(lvls)
+
+	log.exchange(func() {
+		log.close()
+		if lc.format != nil {
+			log.logfmt = lc.format
+		}
+		log.writer = lc.writer
+	})
+
+	size := 0
+	if lc.async != nil {
+		size = *lc.async
+	}
+	log.setAsync(size)
 }
 
-func (log *Log) configJSON(filename string) error {
+func (lc *logConfig) loadJSON(filename string) error {
 	fp, err := os.Open(filename)
 	if err != nil {
 		return err
@@ -37,19 +114,25 @@ func (log *Log) configJSON(filename string) error {
 		return err
 	}
 
-	if err := log.configLogAsync(c); err != nil {
+	if err := lc.configLogAsync(c); err != nil {
 		return err
 	}
-	if err := log.configLogFormat(c); err != nil {
+	if err := lc.configLogFormat(c); err != nil {
+		return err
+	}
+	if err := lc.configLogFatal(c); err != nil {
+		return err
+	}
+	if err := lc.configLogHook(c); err != nil {
 		return err
 	}
 
 	if lvl, ok := c["level"]; ok {
 		switch lvls := lvl.(type) {
 		case string:
-			log.SetLevel(ParseLevel(lvls))
+			lc.level = ParseLevel(lvls)
 		case map[string]interface{}:
-			if err := log.configLogLevels(lvls); err != nil {
+			if err := lc.configLogLevels(lvls); err != nil {
 				return err
 			}
 		}
@@ -57,7 +140,7 @@ func (log *Log) configJSON(filename string) error {
 
 	if v, ok := c["writer"]; ok {
 		if a, ok := v.([]interface{}); ok {
-			if err := log.configLogWriter(a); err != nil {
+			if err := lc.configLogWriter(a); err != nil {
 				return err
 			}
 		} else {
@@ -69,7 +152,7 @@ func (log *Log) configJSON(filename string) error {
 	return nil
 }
 
-func (log *Log) configINI(filename string) error {
+func (lc *logConfig) loadINI(filename string) error {
 	ini := ini.NewIni()
 	if err := ini.LoadFile(filename); err != nil {
 		return err
@@ -77,41 +160,39 @@ func (log *Log) configINI(filename string) error {
 
 	c := ini.Section("").Map()
 
-	if err := log.configLogAsync(c); err != nil {
+	if err := lc.configLogAsync(c); err != nil {
 		return err
 	}
-	if err := log.configLogFormat(c); err != nil {
+	if sec := ini.Section("async"); sec != nil {
+		if err := lc.configLogAsyncMap(sec.Map()); err != nil {
+			return err
+		}
+	}
+	if err := lc.configLogFormat(c); err != nil {
+		return err
+	}
+	if err := lc.configLogFatal(c); err != nil {
+		return err
+	}
+	if err := lc.configLogHook(c); err != nil {
 		return err
 	}
 
 	sec := ini.Section("level")
 	if sec != nil {
 		lvls := sec.Map()
-		if err := log.configLogLevels(lvls); err != nil {
+		if err := lc.configLogLevels(lvls); err != nil {
 			return err
 		}
 	}
 
 	if v, ok := c["writer"]; ok {
 		if s, ok := v.(string); ok {
-			ss := str.FieldsAny(s, " ,")
-			a := make([]interface{}, len(ss))
-			for i, w := range ss {
-				var es map[string]interface{}
-
-				sec := ini.Section("writer." + w)
-				if sec == nil {
-					es = make(map[string]interface{}, 1)
-				} else {
-					es = sec.Map()
-				}
-
-				if _, ok := es["_"]; !ok {
-					es["_"] = w
-				}
-				a[i] = es
+			a, err := iniWriterItems(ini, s, nil)
+			if err != nil {
+				return err
 			}
-			if err := log.configLogWriter(a); err != nil {
+			if err := lc.configLogWriter(a); err != nil {
 				return err
 			}
 		} else {
@@ -123,23 +204,102 @@ func (log *Log) configINI(filename string) error {
 	return nil
 }
 
-func (log *Log) configLogAsync(m map[string]interface{}) error {
+// iniWriterItems get the writer configuration maps of the writer names 's' from the ini sections [writer.xxx].
+// The writer names of the property "routes" (RouteWriter) are resolved to the writer configuration maps.
+func iniWriterItems(ini *ini.Ini, s string, parents []string) ([]interface{}, error) {
+	ss := str.FieldsAny(s, " ,")
+	a := make([]interface{}, len(ss))
+	for i, w := range ss {
+		for _, p := range parents {
+			if p == w {
+				return nil, fmt.Errorf("Circular writer routes: %v", w)
+			}
+		}
+
+		var es map[string]interface{}
+
+		sec := ini.Section("writer." + w)
+		if sec == nil {
+			es = make(map[string]interface{}, 1)
+		} else {
+			es = sec.Map()
+		}
+
+		if _, ok := es["_"]; !ok {
+			es["_"] = w
+		}
+
+		if v, ok := es["routes"]; ok {
+			rs, err := iniWriterItems(ini, fmt.Sprint(v), append(parents, w))
+			if err != nil {
+				return nil, err
+			}
+			es["routes"] = rs
+		}
+		a[i] = es
+	}
+	return a, nil
+}
+
+func (lc *logConfig) configLogAsync(m map[string]interface{}) error {
 	if v, ok := m["async"]; ok {
 		if v != nil {
+			if am, ok := v.(map[string]interface{}); ok {
+				return lc.configLogAsyncMap(am)
+			}
+
 			n, err := ref.Convert(v, reflect.TypeOf(int(0)))
 			if err != nil {
 				return fmt.Errorf("Invalid async value %v: %s", v, err.Error())
 			}
-			log.Async(n.(int))
+			size := n.(int)
+			lc.async = &size
+		}
+	}
+	return nil
+}
+
+func (lc *logConfig) configLogAsyncMap(m map[string]interface{}) error {
+	if v, ok := m["overflow"]; ok {
+		if s, ok := v.(string); ok {
+			ov, err := ParseOverflow(s)
+			if err != nil {
+				return err
+			}
+			lc.overflow = &ov
+		} else {
+			return fmt.Errorf("Invalid async overflow value: %v", v)
+		}
+	}
+
+	if v, ok := m["level"]; ok {
+		if s, ok := v.(string); ok {
+			lvl := ParseLevel(s)
+			lc.ovlevel = &lvl
+		} else {
+			return fmt.Errorf("Invalid async level value: %v", v)
+		}
+	}
+
+	if v, ok := m["size"]; ok && v != nil {
+		n, err := ref.Convert(v, reflect.TypeOf(int(0)))
+		if err != nil {
+			return fmt.Errorf("Invalid async size %v: %s", v, err.Error())
 		}
+		size := n.(int)
+		lc.async = &size
+	}
+
+	if lc.async == nil {
+		return errors.New("Missing async size")
 	}
 	return nil
 }
 
-func (log *Log) configLogFormat(m map[string]interface{}) error {
+func (lc *logConfig) configLogFormat(m map[string]interface{}) error {
 	if v, ok := m["format"]; ok {
 		if s, ok := v.(string); ok {
-			log.SetFormatter(NewLogFormatter(s))
+			lc.format = NewLogFormatter(s)
 		} else {
 			return fmt.Errorf("Invalid format value: %v", v)
 		}
@@ -147,13 +307,44 @@ func (log *Log) configLogFormat(m map[string]interface{}) error {
 	return nil
 }
 
-func (log *Log) configLogLevels(lls map[string]interface{}) error {
+func (lc *logConfig) configLogFatal(m map[string]interface{}) error {
+	if v, ok := m["fatal"]; ok {
+		if s, ok := v.(string); ok {
+			fh, err := ParseFatalHandler(s)
+			if err != nil {
+				return err
+			}
+			lc.fatal = &fh
+		} else {
+			return fmt.Errorf("Invalid fatal value: %v", v)
+		}
+	}
+	return nil
+}
+
+func (lc *logConfig) configLogHook(m map[string]interface{}) error {
+	if v, ok := m["hook"]; ok {
+		if s, ok := v.(string); ok {
+			hs, err := NewLogHooks(s)
+			if err != nil {
+				return err
+			}
+			lc.hooks = hs
+			lc.hooked = true
+		} else {
+			return fmt.Errorf("Invalid hook value: %v", v)
+		}
+	}
+	return nil
+}
+
+func (lc *logConfig) configLogLevels(lls map[string]interface{}) error {
 	lvls := map[string]Level{}
 
 	for k, v := range lls {
 		if s, ok := v.(string); ok {
 			if k == "*" {
-				log.SetLevel(ParseLevel(s))
+				lc.level = ParseLevel(s)
 			} else {
 				lvl := ParseLevel(s)
 				if lvl != LevelNone {
@@ -165,38 +356,49 @@ func (log *Log) configLogLevels(lls map[string]interface{}) error {
 		}
 	}
 
-	log.levels = lvls
+	lc.levels = lvls
 	return nil
 }
 
-func (log *Log) configLogWriter(a []interface{}) error {
+func (lc *logConfig) configLogWriter(a []interface{}) error {
 	var ws []Writer
 	for _, i := range a {
 		if c, ok := i.(map[string]interface{}); ok {
 			if n, ok := c["_"]; ok {
 				w := CreateWriter(n.(string))
 				if w == nil {
+					closeWriters(ws)
 					return fmt.Errorf("Invalid writer name: %v", n)
 				}
 				if err := ConfigWriter(w, c); err != nil {
+					closeWriters(append(ws, w))
 					return err
 				}
 				ws = append(ws, w)
 			} else {
+				closeWriters(ws)
 				return fmt.Errorf("Missing writer type: %v", c)
 			}
 		} else {
+			closeWriters(ws)
 			return fmt.Errorf("Invalid writer item: %v", i)
 		}
 	}
-	if len(ws) < 0 {
+	if len(ws) < 1 {
 		return fmt.Errorf("Empty writer configuration: %v", a)
 	}
 
 	if len(ws) == 1 {
-		log.SetWriter(ws[0])
+		lc.writer = ws[0]
 	} else {
-		log.SetWriter(&MultiWriter{Writers: ws})
+		lc.writer = &MultiWriter{Writers: ws}
 	}
 	return nil
 }
+
+// closeWriters close the writers created by a failed configuration
+func closeWriters(ws []Writer) {
+	for _, w := range ws {
+		w.Close()
+	}
+}
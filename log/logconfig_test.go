@@ -23,6 +23,11 @@ func TestLogConfigINI(t *testing.T) {
 }
 
 func assertLogConfig(t *testing.T, log *Log) {
+	assert.True(t, log.async)
+	assert.Equal(t, 1000, cap(log.evtChan))
+	assert.Equal(t, OverflowDropBelow, log.GetOverflow())
+	assert.Equal(t, LevelWarn, log.GetOverflowLevel())
+
 	assert.Equal(t, LevelInfo, log.GetLevel())
 	assert.Equal(t, 2, len(log.levels))
 	assert.Equal(t, LevelDebug, log.levels["sql"])
@@ -193,3 +198,38 @@ func TestLogConfigFile2(t *testing.T) {
 	bs, _ = ioutil.ReadFile("conftest/logs/file2.log")
 	assert.Equal(t, "WARN - This is WARN."+eol+"ERROR - This is ERROR."+eol, string(bs))
 }
+
+func TestLogConfigReset(t *testing.T) {
+	log := NewLog()
+	assert.Nil(t, log.Config("testdata/log.json"))
+	assert.True(t, log.async)
+	assert.Equal(t, LevelInfo, log.GetLevel())
+
+	path := t.TempDir() + "/log.json"
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"writer": [{"_": "memory"}]}`), 0666))
+	assert.Nil(t, log.Config(path))
+
+	assert.False(t, log.async)
+	assert.Equal(t, LevelTrace, log.GetLevel())
+	assert.Equal(t, 0, len(log.GetLevels()))
+	assert.Equal(t, LevelTrace, log.GetLogger("sql").GetLevel())
+	_, ok := log.GetWriter().(*MemoryWriter)
+	assert.True(t, ok)
+	log.Close()
+}
+
+func TestLogConfigAsyncNoSize(t *testing.T) {
+	log := NewLog()
+	log.Async(10)
+	defer log.Close()
+
+	path := t.TempDir() + "/log.json"
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"async": {"overflow": "drop-newest"}, "writer": [{"_": "memory"}]}`), 0666))
+	assert.NotNil(t, log.Config(path))
+	assert.True(t, log.async)
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"async": {"size": 100, "overflow": "drop-newest"}, "writer": [{"_": "memory"}]}`), 0666))
+	assert.Nil(t, log.Config(path))
+	assert.True(t, log.async)
+	assert.Equal(t, OverflowDropNewest, log.GetOverflow())
+}
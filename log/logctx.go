@@ -0,0 +1,193 @@
+package log
+
+import (
+	"context"
+	"sync"
+)
+
+// context key type
+type ctxkey int
+
+const (
+	ctxLoggerKey ctxkey = iota
+	ctxFieldsKey
+)
+
+// Field names of the request-scoped values
+const (
+	FieldRequestID = "requestID"
+	FieldTraceID   = "traceID"
+)
+
+var (
+	ctxkmutex   sync.RWMutex
+	contextKeys = make(map[string]interface{})
+)
+
+// RegisterContextKey register a context value key,
+// the value of the key in the context will be logged as the field 'name' by the XxxCtx() methods
+func RegisterContextKey(name string, key interface{}) {
+	ctxkmutex.Lock()
+	contextKeys[name] = key
+	ctxkmutex.Unlock()
+}
+
+// NewContext returns a copy of ctx that carries the logger 'lg'
+func NewContext(ctx context.Context, lg Logger) context.Context {
+	return context.WithValue(ctx, ctxLoggerKey, lg)
+}
+
+// FromContext returns the Logger carried by ctx, or the default Log if ctx has no Logger
+func FromContext(ctx context.Context) Logger {
+	if ctx != nil {
+		if lg, ok := ctx.Value(ctxLoggerKey).(Logger); ok {
+			return lg
+		}
+	}
+	return _log
+}
+
+// ContextWithFields returns a copy of ctx with the key/value pairs 'kvs' added to the request-scoped fields
+// example: ctx = log.ContextWithFields(ctx, log.FieldRequestID, rid)
+func ContextWithFields(ctx context.Context, kvs ...interface{}) context.Context {
+	fs, _ := ctx.Value(ctxFieldsKey).(map[string]interface{})
+	return context.WithValue(ctx, ctxFieldsKey, addFields(fs, kvs))
+}
+
+// ContextWithRequestID returns a copy of ctx with the request ID field
+func ContextWithRequestID(ctx context.Context, rid string) context.Context {
+	return ContextWithFields(ctx, FieldRequestID, rid)
+}
+
+// ContextWithTraceID returns a copy of ctx with the trace ID field
+func ContextWithTraceID(ctx context.Context, tid string) context.Context {
+	return ContextWithFields(ctx, FieldTraceID, tid)
+}
+
+// ContextFields returns the request-scoped fields of ctx (include the registered context keys)
+func ContextFields(ctx context.Context) map[string]interface{} {
+	if ctx == nil {
+		return nil
+	}
+
+	fs, _ := ctx.Value(ctxFieldsKey).(map[string]interface{})
+
+	ctxkmutex.RLock()
+	defer ctxkmutex.RUnlock()
+
+	if len(contextKeys) == 0 {
+		return fs
+	}
+
+	var nm map[string]interface{}
+	for n, k := range contextKeys {
+		if v := ctx.Value(k); v != nil {
+			if nm == nil {
+				nm = make(map[string]interface{}, len(fs)+len(contextKeys))
+				for k, v := range fs {
+					nm[k] = v
+				}
+			}
+			nm[n] = v
+		}
+	}
+	if nm == nil {
+		return fs
+	}
+	return nm
+}
+
+// mergeFields merge the fields 'fs' and the request-scoped fields 'cfs'
+func mergeFields(fs, cfs map[string]interface{}) map[string]interface{} {
+	if len(cfs) == 0 {
+		return fs
+	}
+	if len(fs) == 0 {
+		return cfs
+	}
+
+	nm := make(map[string]interface{}, len(fs)+len(cfs))
+	for k, v := range fs {
+		nm[k] = v
+	}
+	for k, v := range cfs {
+		nm[k] = v
+	}
+	return nm
+}
+
+// ctxLogger returns the internal logger of the Logger carried by ctx,
+// or nil and the Logger if the Logger is not implemented by this package
+func ctxLogger(ctx context.Context) (*logger, Logger) {
+	switch lg := FromContext(ctx).(type) {
+	case *logger:
+		return lg, lg
+	case *Log:
+		return lg.logger, lg
+	default:
+		return nil, lg
+	}
+}
+
+// LogCtx log a message at specified level with the logger and the request-scoped fields of ctx.
+func LogCtx(ctx context.Context, lvl Level, v ...interface{}) {
+	if l, lg := ctxLogger(ctx); l != nil {
+		l._logCtx(ctx, lvl, v...)
+	} else {
+		lg.LogCtx(ctx, lvl, v...)
+	}
+}
+
+// FatalCtx log a message at fatal level with the logger and the request-scoped fields of ctx.
+func FatalCtx(ctx context.Context, v ...interface{}) {
+	if l, lg := ctxLogger(ctx); l != nil {
+		l._logCtx(ctx, LevelFatal, v...)
+	} else {
+		lg.LogCtx(ctx, LevelFatal, v...)
+	}
+}
+
+// ErrorCtx log a message at error level with the logger and the request-scoped fields of ctx.
+func ErrorCtx(ctx context.Context, v ...interface{}) {
+	if l, lg := ctxLogger(ctx); l != nil {
+		l._logCtx(ctx, LevelError, v...)
+	} else {
+		lg.LogCtx(ctx, LevelError, v...)
+	}
+}
+
+// WarnCtx log a message at warning level with the logger and the request-scoped fields of ctx.
+func WarnCtx(ctx context.Context, v ...interface{}) {
+	if l, lg := ctxLogger(ctx); l != nil {
+		l._logCtx(ctx, LevelWarn, v...)
+	} else {
+		lg.LogCtx(ctx, LevelWarn, v...)
+	}
+}
+
+// InfoCtx log a message at info level with the logger and the request-scoped fields of ctx.
+func InfoCtx(ctx context.Context, v ...interface{}) {
+	if l, lg := ctxLogger(ctx); l != nil {
+		l._logCtx(ctx, LevelInfo, v...)
+	} else {
+		lg.LogCtx(ctx, LevelInfo, v...)
+	}
+}
+
+// DebugCtx log a message at debug level with the logger and the request-scoped fields of ctx.
+func DebugCtx(ctx context.Context, v ...interface{}) {
+	if l, lg := ctxLogger(ctx); l != nil {
+		l._logCtx(ctx, LevelDebug, v...)
+	} else {
+		lg.LogCtx(ctx, LevelDebug, v...)
+	}
+}
+
+// TraceCtx log a message at trace level with the logger and the request-scoped fields of ctx.
+func TraceCtx(ctx context.Context, v ...interface{}) {
+	if l, lg := ctxLogger(ctx); l != nil {
+		l._logCtx(ctx, LevelTrace, v...)
+	} else {
+		lg.LogCtx(ctx, LevelTrace, v...)
+	}
+}
@@ -0,0 +1,67 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCtxKey string
+
+func TestLogContext(t *testing.T) {
+	bb := &bytes.Buffer{}
+
+	log := NewLog()
+	log.SetFormatter(NewTextFormatter("[%c] %l %S:%L %F() - %m %W%n"))
+	log.SetWriter(&StreamWriter{Output: bb})
+
+	ctx := context.Background()
+	assert.Equal(t, _log, FromContext(ctx))
+
+	lg := log.GetLogger("ctx").With("user", "u1")
+	ctx = NewContext(ctx, lg)
+	assert.Equal(t, lg, FromContext(ctx))
+
+	ctx = ContextWithRequestID(ctx, "r1")
+	ctx = ContextWithTraceID(ctx, "t1")
+
+	file, line, ffun := testGetCaller(1)
+	InfoCtx(ctx, "hello")
+	assert.Equal(t, fmt.Sprintf("[ctx] INFO %s:%d %s() - hello requestID=r1 traceID=t1 user=u1%s", file, line, ffun, eol), bb.String())
+
+	bb.Reset()
+	file, line, ffun = testGetCaller(1)
+	lg.WarnCtx(ctx, "hello")
+	assert.Equal(t, fmt.Sprintf("[ctx] WARN %s:%d %s() - hello requestID=r1 traceID=t1 user=u1%s", file, line, ffun, eol), bb.String())
+}
+
+func TestLogContextKey(t *testing.T) {
+	RegisterContextKey("tenant", testCtxKey("tenant"))
+	defer delete(contextKeys, "tenant")
+
+	ctx := context.WithValue(context.Background(), testCtxKey("tenant"), "t1")
+	ctx = ContextWithFields(ctx, "a", 1)
+	assert.Equal(t, map[string]interface{}{"a": 1, "tenant": "t1"}, ContextFields(ctx))
+}
+
+type testCustomLogger struct {
+	Logger
+	lvls []Level
+}
+
+func (tl *testCustomLogger) LogCtx(ctx context.Context, lvl Level, v ...interface{}) {
+	tl.lvls = append(tl.lvls, lvl)
+}
+
+func TestLogContextCustomLogger(t *testing.T) {
+	tl := &testCustomLogger{Logger: NewLog()}
+	ctx := NewContext(context.Background(), tl)
+
+	ErrorCtx(ctx, "error")
+	InfoCtx(ctx, "info")
+	LogCtx(ctx, LevelDebug, "debug")
+	assert.Equal(t, []Level{LevelError, LevelInfo, LevelDebug}, tl.lvls)
+}
@@ -0,0 +1,217 @@
+package log
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ErrorCause a wrapped error (cause) of the logged error
+type ErrorCause struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// ErrorInfo the info of the error logged by Logger.Error(err) (or any other log function with a error argument)
+type ErrorInfo struct {
+	Type    string        `json:"type"`             // the error type (e.g. "*fs.PathError")
+	Message string        `json:"message"`          // the error message
+	Causes  []*ErrorCause `json:"causes,omitempty"` // the wrapped errors (Unwrap() error / []error, depth first)
+	Stack   string        `json:"stack,omitempty"`  // the stack trace where the origin error was created
+}
+
+// NewErrorInfo create the error info of the error 'err'.
+// The causes are the wrapped errors of the methods Unwrap() error and Unwrap() []error (depth first).
+// The stack trace is got from the deepest error (in the unwrap chain) which has a method StackTrace()
+// that returns a slice of program counters (the errors created by WithStack() or github.com/pkg/errors).
+func NewErrorInfo(err error) *ErrorInfo {
+	ei := &ErrorInfo{
+		Type:    fmt.Sprintf("%T", err),
+		Message: errorMessage(err),
+	}
+
+	var pcs []uintptr
+	walkErrors(err, func(e error) {
+		if e != err {
+			ei.Causes = append(ei.Causes, &ErrorCause{Type: fmt.Sprintf("%T", e), Message: errorMessage(e)})
+		}
+		if st := errorStackTrace(e); len(st) > 0 {
+			pcs = st
+		}
+	})
+
+	if len(pcs) > 0 {
+		ei.Stack = stackTrace(pcs)
+	}
+	return ei
+}
+
+// walkErrors call fn with the error 'err' and the wrapped errors of it (depth first)
+func walkErrors(err error, fn func(error)) {
+	fn(err)
+	for _, e := range unwrapErrors(err) {
+		walkErrors(e, fn)
+	}
+}
+
+// unwrapErrors return the wrapped errors by the method Unwrap() error or Unwrap() []error.
+// It returns nil if the method panics (e.g. a nil pointer receiver).
+func unwrapErrors(err error) (errs []error) {
+	defer func() {
+		if recover() != nil {
+			errs = nil
+		}
+	}()
+
+	switch x := err.(type) {
+	case interface{ Unwrap() error }:
+		if e := x.Unwrap(); e != nil {
+			errs = []error{e}
+		}
+	case interface{ Unwrap() []error }:
+		for _, e := range x.Unwrap() {
+			if e != nil {
+				errs = append(errs, e)
+			}
+		}
+	}
+	return
+}
+
+// errorMessage return err.Error() like fmt.Sprint(err),
+// "<nil>" if the method Error() panics with a nil pointer receiver.
+func errorMessage(err error) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if v := reflect.ValueOf(err); v.Kind() == reflect.Ptr && v.IsNil() {
+				msg = "<nil>"
+			} else {
+				msg = fmt.Sprintf("%%!v(PANIC=Error method: %v)", r)
+			}
+		}
+	}()
+
+	return err.Error()
+}
+
+// String return the error info as text: the type and message of the error and the causes, and the stack trace
+func (ei *ErrorInfo) String() string {
+	sb := &strings.Builder{}
+	sb.WriteString(ei.Type)
+	sb.WriteString(": ")
+	sb.WriteString(ei.Message)
+	sb.WriteString(eol)
+	sb.WriteString(ei.CausesString())
+	sb.WriteString(ei.Stack)
+	return sb.String()
+}
+
+// CausesString return the causes as text lines "Caused by: type: message"
+func (ei *ErrorInfo) CausesString() string {
+	sb := &strings.Builder{}
+	for _, c := range ei.Causes {
+		sb.WriteString("Caused by: ")
+		sb.WriteString(c.Type)
+		sb.WriteString(": ")
+		sb.WriteString(c.Message)
+		sb.WriteString(eol)
+	}
+	return sb.String()
+}
+
+// findErrorInfo find the first error in the arguments 'v' and create the error info
+func findErrorInfo(v []interface{}) *ErrorInfo {
+	for _, a := range v {
+		if err, ok := a.(error); ok && err != nil {
+			return NewErrorInfo(err)
+		}
+	}
+	return nil
+}
+
+// stackError a error with the stack trace
+type stackError struct {
+	err error
+	pcs []uintptr
+}
+
+// WithStack return a error wraps the error 'err' with the stack trace of the caller, nil if err is nil.
+func WithStack(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	return &stackError{err: err, pcs: pcs[:n]}
+}
+
+// Error return the message of the wrapped error
+func (se *stackError) Error() string {
+	return se.err.Error()
+}
+
+// Unwrap return the wrapped error
+func (se *stackError) Unwrap() error {
+	return se.err
+}
+
+// StackTrace return the program counters of the stack trace
+func (se *stackError) StackTrace() []uintptr {
+	return se.pcs
+}
+
+// errorStackTrace get the program counters of the error by the method StackTrace()
+// which returns a slice of uintptr (e.g. github.com/pkg/errors.StackTrace)
+func errorStackTrace(err error) (pcs []uintptr) {
+	defer func() {
+		if recover() != nil {
+			pcs = nil
+		}
+	}()
+
+	if se, ok := err.(*stackError); ok {
+		return se.pcs
+	}
+
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+
+	t := m.Type().Out(0)
+	if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	r := m.Call(nil)[0]
+	pcs = make([]uintptr, r.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(r.Index(i).Uint())
+	}
+	return pcs
+}
+
+// stackTrace format the program counters as the trace lines "file:line function()"
+func stackTrace(pcs []uintptr) string {
+	sb := &strings.Builder{}
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, next := frames.Next()
+		if frame.Function != "" {
+			sb.WriteString(frame.File)
+			sb.WriteString(":")
+			sb.WriteString(strconv.Itoa(frame.Line))
+			sb.WriteString(" ")
+			sb.WriteString(frame.Function)
+			sb.WriteString("()")
+			sb.WriteString(eol)
+		}
+		if !next {
+			break
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,205 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testFrame a frame like github.com/pkg/errors.Frame
+type testFrame uintptr
+
+// testStackError a error like github.com/pkg/errors.fundamental
+type testStackError struct {
+	msg   string
+	stack []testFrame
+}
+
+func newTestStackError(msg string) error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+
+	fs := make([]testFrame, n)
+	for i := 0; i < n; i++ {
+		fs[i] = testFrame(pcs[i])
+	}
+	return &testStackError{msg: msg, stack: fs}
+}
+
+func (tse *testStackError) Error() string {
+	return tse.msg
+}
+
+func (tse *testStackError) StackTrace() []testFrame {
+	return tse.stack
+}
+
+func createTestError() error {
+	return WithStack(errors.New("disk full"))
+}
+
+func TestErrorInfo(t *testing.T) {
+	mw := NewMemoryWriter(10)
+	log := NewLog()
+	log.SetWriter(mw)
+
+	err := fmt.Errorf("save failed: %w", createTestError())
+	log.Error("failed to save: ", err)
+	log.Errorf("failed to save: %v", err)
+	log.Info("no error")
+
+	les := mw.Snapshot()
+	for _, le := range les[:2] {
+		ei := le.Error
+		if !assert.NotNil(t, ei) {
+			return
+		}
+
+		assert.Equal(t, "*fmt.wrapError", ei.Type)
+		assert.Equal(t, "save failed: disk full", ei.Message)
+		assert.Equal(t, []*ErrorCause{
+			{Type: "*log.stackError", Message: "disk full"},
+			{Type: "*errors.errorString", Message: "disk full"},
+		}, ei.Causes)
+		assert.Contains(t, ei.Stack, "logerror_test.go:")
+		assert.Contains(t, ei.Stack, "log.createTestError()")
+	}
+	assert.Nil(t, les[2].Error)
+}
+
+func TestErrorInfoStackTracer(t *testing.T) {
+	ei := NewErrorInfo(newTestStackError("pkg error"))
+	assert.Equal(t, "*log.testStackError", ei.Type)
+	assert.Equal(t, "pkg error", ei.Message)
+	assert.Nil(t, ei.Causes)
+	assert.Contains(t, ei.Stack, "log.TestErrorInfoStackTracer()")
+
+	ei = NewErrorInfo(errors.New("no stack"))
+	assert.Equal(t, "", ei.Stack)
+
+	assert.Nil(t, WithStack(nil))
+}
+
+// testPtrError a error which methods dereference the pointer receiver
+type testPtrError struct {
+	msg string
+	err error
+}
+
+func (tpe *testPtrError) Error() string {
+	return tpe.msg
+}
+
+func (tpe *testPtrError) Unwrap() error {
+	return tpe.err
+}
+
+func (tpe *testPtrError) StackTrace() []uintptr {
+	return nil
+}
+
+func TestErrorInfoNilPointer(t *testing.T) {
+	var tpe *testPtrError
+
+	ei := NewErrorInfo(tpe)
+	assert.Equal(t, "*log.testPtrError", ei.Type)
+	assert.Equal(t, "<nil>", ei.Message)
+	assert.Nil(t, ei.Causes)
+
+	ei = NewErrorInfo(fmt.Errorf("wrap: %w", tpe))
+	assert.Equal(t, "wrap: <nil>", ei.Message)
+	assert.Equal(t, []*ErrorCause{{Type: "*log.testPtrError", Message: "<nil>"}}, ei.Causes)
+
+	mw := NewMemoryWriter(10)
+	log := NewLog()
+	log.SetWriter(mw)
+	log.Error("failed: ", tpe)
+
+	les := mw.Snapshot()
+	if assert.Equal(t, 1, len(les)) && assert.NotNil(t, les[0].Error) {
+		assert.Equal(t, "<nil>", les[0].Error.Message)
+	}
+}
+
+// testMultiError a error which wraps multiple errors like errors.Join()
+type testMultiError struct {
+	errs []error
+}
+
+func (tme *testMultiError) Error() string {
+	return "multiple errors"
+}
+
+func (tme *testMultiError) Unwrap() []error {
+	return tme.errs
+}
+
+func TestErrorInfoMultiError(t *testing.T) {
+	err := &testMultiError{errs: []error{
+		&testPtrError{msg: "a", err: errors.New("a1")},
+		nil,
+		createTestError(),
+	}}
+
+	ei := NewErrorInfo(fmt.Errorf("wrap: %w", err))
+	assert.Equal(t, []*ErrorCause{
+		{Type: "*log.testMultiError", Message: "multiple errors"},
+		{Type: "*log.testPtrError", Message: "a"},
+		{Type: "*errors.errorString", Message: "a1"},
+		{Type: "*log.stackError", Message: "disk full"},
+		{Type: "*errors.errorString", Message: "disk full"},
+	}, ei.Causes)
+	assert.Contains(t, ei.Stack, "log.createTestError()")
+}
+
+func testErrorEvent() *Event {
+	le := newEvent(NewLog().GetLogger("app"), LevelError, "failed")
+	le.When = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	le.File = ""
+	le.Error = &ErrorInfo{
+		Type:    "*fmt.wrapError",
+		Message: "save: disk full",
+		Causes:  []*ErrorCause{{Type: "*errors.errorString", Message: "disk full"}},
+		Stack:   "a.go:1 main.main()" + eol,
+	}
+	return le
+}
+
+func TestErrorFormatText(t *testing.T) {
+	le := testErrorEvent()
+
+	assert.Equal(t, "failed: save: disk full (*fmt.wrapError)", NewTextFormatter("%m: %e (%e{type})").Format(le))
+	assert.Equal(t, "Caused by: *errors.errorString: disk full"+eol, NewTextFormatter("%e{causes}").Format(le))
+	assert.Equal(t, "a.go:1 main.main()"+eol, NewTextFormatter("%e{stack}").Format(le))
+	assert.Equal(t, "failed"+eol+"*fmt.wrapError: save: disk full"+eol+"Caused by: *errors.errorString: disk full"+eol+"a.go:1 main.main()"+eol, NewTextFormatter("%m%n%E").Format(le))
+
+	le.Error = nil
+	assert.Equal(t, "failed  ", NewTextFormatter("%m %e %E").Format(le))
+}
+
+func TestErrorFormatJSON(t *testing.T) {
+	le := testErrorEvent()
+
+	assert.Equal(t, `{"msg": "failed", "error": "save: disk full", "type": "*fmt.wrapError", "causes": [{"type":"*errors.errorString","message":"disk full"}]}`,
+		NewJSONFormatter(`{"msg": %m, "error": %e, "type": %e{type}, "causes": %e{causes}}`).Format(le))
+	assert.Equal(t, `{"error": {"type":"*fmt.wrapError","message":"save: disk full","causes":[{"type":"*errors.errorString","message":"disk full"}],"stack":"a.go:1 main.main()\n"}}`,
+		strings.Replace(NewJSONFormatter(`{"error": %E}`).Format(le), `\r\n`, `\n`, -1))
+
+	le.Error = nil
+	assert.Equal(t, `{"error": "", "causes": [], "info": null}`, NewJSONFormatter(`{"error": %e, "causes": %e{causes}, "info": %E}`).Format(le))
+}
+
+func TestErrorFormatLogfmt(t *testing.T) {
+	le := testErrorEvent()
+	assert.Equal(t, `msg=failed error="save: disk full" error_type=*fmt.wrapError`, NewLogfmtFormatter("msg=%m error=%e error_type=%e{type}").Format(le))
+}
+
+func TestErrorFormatECS(t *testing.T) {
+	le := testErrorEvent()
+	assert.Equal(t, `{"@timestamp":"2020-01-02T03:04:05.000Z","ecs.version":"1.6.0","error.message":"save: disk full","error.stack_trace":"a.go:1 main.main()`+strings.Replace(fmt.Sprintf("%q", eol), `"`, "", -1)+`","error.type":"*fmt.wrapError","log.level":"error","log.logger":"app","message":"failed"}`+eol, NewECSFormatter().Format(le))
+}
@@ -14,11 +14,15 @@ type Event struct {
 	Logger Logger    `json:"-"`
 	Level  Level     `json:"level"`
 	Msg    string    `json:"msg"`
+	Format string    `json:"-"` // the format string of the message logged by Logf(), Errorf(), ...
 	When   time.Time `json:"when"`
 	File   string    `json:"file"`
 	Line   int       `json:"line"`
 	Func   string    `json:"func"`
 	Trace  string    `json:"trace"`
+
+	Fields map[string]interface{} `json:"fields,omitempty"`
+	Error  *ErrorInfo             `json:"error,omitempty"`
 }
 
 // eventPool log event pool
@@ -33,10 +37,13 @@ func (le *Event) Clear() {
 	le.Logger = nil
 	le.Level = LevelNone
 	le.Msg = ""
+	le.Format = ""
 	le.File = ""
 	le.Line = 0
 	le.Func = ""
 	le.Trace = ""
+	le.Fields = nil
+	le.Error = nil
 }
 
 // Caller get caller filename and line number
@@ -80,7 +87,9 @@ func newEvent(logger Logger, lvl Level, msg string) *Event {
 	le.Logger = logger
 	le.Level = lvl
 	le.Msg = msg
+	le.Format = ""
 	le.When = time.Now()
+	le.Fields = logger.GetFields()
 	le.File = ""
 	le.Line = 0
 	le.Trace = ""
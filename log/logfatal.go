@@ -0,0 +1,140 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// osExit the exit function (replaceable for test)
+var osExit = os.Exit
+
+// FatalHandler the handler which is called after a FATAL event is written and the log writers are flushed.
+// The event is a copy of the FATAL event.
+type FatalHandler func(le *Event)
+
+// FatalExit return a FatalHandler which exits the process with the code 'code'
+func FatalExit(code int) FatalHandler {
+	return func(le *Event) {
+		osExit(code)
+	}
+}
+
+// ParseFatalHandler parse the fatal handler configuration:
+// "exit" (exit with code 1), "exit:N" (exit with code N), "" or "none" (do nothing)
+func ParseFatalHandler(s string) (FatalHandler, error) {
+	switch s {
+	case "", "none":
+		return nil, nil
+	case "exit":
+		return FatalExit(1), nil
+	}
+
+	if strings.HasPrefix(s, "exit:") {
+		code, err := strconv.Atoi(s[5:])
+		if err == nil {
+			return FatalExit(code), nil
+		}
+	}
+	return nil, fmt.Errorf("Invalid fatal handler %q", s)
+}
+
+// GetFatalHandler get the fatal handler
+func (log *Log) GetFatalHandler() FatalHandler {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	return log.fatalh
+}
+
+// SetFatalHandler set the fatal handler (nil: do nothing after a FATAL event is logged)
+func (log *Log) SetFatalHandler(fh FatalHandler) {
+	log.mutex.Lock()
+	log.fatalh = fh
+	log.mutex.Unlock()
+}
+
+// fatal flush the log (write the queued events in async mode) and call the fatal handler with the copy of the FATAL event
+func (log *Log) fatal(fh FatalHandler, le *Event) {
+	log.Flush()
+	fh(le)
+}
+
+// Recover recover the panic and log it as a ERROR event with the stack trace of the panic.
+// The log is flushed, and the panic is re-panicked if 'repanic' is true.
+// It must be called by defer directly, example:
+//
+//	go func() {
+//		defer log.Recover()
+//		...
+//	}()
+func (log *Log) Recover(repanic ...bool) {
+	if r := recover(); r != nil {
+		log.panic(r, len(repanic) > 0 && repanic[0])
+	}
+}
+
+// panic log the recovered panic 'r', flush the log, and re-panic if 'repanic' is true
+func (log *Log) panic(r interface{}, repanic bool) {
+	lg := &logger{log: log}
+	if lg.IsErrorEnabled() {
+		le := newEvent(lg, LevelError, fmt.Sprint("panic: ", r))
+		if err, ok := r.(error); ok {
+			le.Error = NewErrorInfo(err)
+		}
+		le.panicCaller()
+		log.submit(le)
+		log.Flush()
+	}
+
+	if repanic {
+		panic(r)
+	}
+}
+
+// panicCaller set the caller info and the stack trace by the frames where the panic occurred
+func (le *Event) panicCaller() {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+
+	var frames []runtime.Frame
+	fs := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, next := fs.Next()
+		frames = append(frames, frame)
+		if !next {
+			break
+		}
+	}
+
+	// skip the frames before the panic
+	for i, f := range frames {
+		if f.Function == "runtime.gopanic" {
+			frames = frames[i+1:]
+			break
+		}
+	}
+	for len(frames) > 1 && strings.HasPrefix(frames[0].Function, "runtime.") {
+		frames = frames[1:]
+	}
+
+	if len(frames) > 0 {
+		_, le.Func = path.Split(frames[0].Function)
+		_, le.File = path.Split(frames[0].File)
+		le.Line = frames[0].Line
+	}
+
+	sb := strings.Builder{}
+	for _, f := range frames {
+		sb.WriteString(f.File)
+		sb.WriteString(":")
+		sb.WriteString(strconv.Itoa(f.Line))
+		sb.WriteString(" ")
+		sb.WriteString(f.Function)
+		sb.WriteString("()")
+		sb.WriteString(eol)
+	}
+	le.Trace = sb.String()
+}
@@ -0,0 +1,201 @@
+package log
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFatalHandler(t *testing.T) {
+	mw := NewMemoryWriter(100)
+	log := NewLog()
+	log.SetWriter(mw)
+	log.Async(100)
+	defer log.Close()
+
+	var fatal *Event
+	var written int
+	log.SetFatalHandler(func(le *Event) {
+		fatal = le
+		written = len(mw.Snapshot())
+	})
+
+	for i := 0; i < 10; i++ {
+		log.Info(i)
+	}
+	log.GetLogger("app").Fatal("boom")
+
+	if assert.NotNil(t, fatal) {
+		assert.Equal(t, "boom", fatal.Msg)
+		assert.Equal(t, "app", fatal.Logger.GetName())
+	}
+	assert.Equal(t, 11, written)
+}
+
+func TestFatalExit(t *testing.T) {
+	defer func() { osExit = os.Exit }()
+
+	code := -1
+	osExit = func(c int) {
+		code = c
+	}
+
+	bw := &testBatchWriter{}
+	log := NewLog()
+	log.SetWriter(bw)
+	log.SetFatalHandler(FatalExit(3))
+
+	log.Error("error")
+	assert.Equal(t, -1, code)
+
+	log.Fatal("fatal")
+	assert.Equal(t, 3, code)
+	assert.Equal(t, []string{"error", "fatal"}, bw.msgs)
+	assert.Equal(t, 1, bw.flushed)
+}
+
+func TestParseFatalHandler(t *testing.T) {
+	for _, s := range []string{"", "none"} {
+		fh, err := ParseFatalHandler(s)
+		assert.Nil(t, err, s)
+		assert.Nil(t, fh, s)
+	}
+
+	for _, s := range []string{"exit", "exit:2"} {
+		fh, err := ParseFatalHandler(s)
+		assert.Nil(t, err, s)
+		assert.NotNil(t, fh, s)
+	}
+
+	for _, s := range []string{"x", "exit:x"} {
+		_, err := ParseFatalHandler(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestFatalConfig(t *testing.T) {
+	defer func() { osExit = os.Exit }()
+
+	code := -1
+	osExit = func(c int) {
+		code = c
+	}
+
+	dir, err := ioutil.TempDir("", "logfatal")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "log.ini")
+	ioutil.WriteFile(file, []byte("writer = memory\nfatal = exit:2\n"), 0666)
+
+	log := NewLog()
+	if !assert.Nil(t, log.Config(file)) {
+		return
+	}
+	log.Fatal("fatal")
+	assert.Equal(t, 2, code)
+
+	ioutil.WriteFile(file, []byte("writer = memory\nfatal = x\n"), 0666)
+	assert.NotNil(t, log.Config(file))
+}
+
+func testPanic(log *Log, wg *sync.WaitGroup) {
+	defer wg.Done()
+	defer log.Recover()
+
+	var m map[string]int
+	m["x"] = 1
+}
+
+func TestRecover(t *testing.T) {
+	mw := NewMemoryWriter(10)
+	log := NewLog()
+	log.SetWriter(mw)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go testPanic(log, wg)
+	wg.Wait()
+
+	les := mw.Snapshot()
+	if assert.Equal(t, 1, len(les)) {
+		le := les[0]
+		assert.Equal(t, LevelError, le.Level)
+		assert.Equal(t, "panic: assignment to entry in nil map", le.Msg)
+		assert.Equal(t, "logfatal_test.go", le.File)
+		assert.Equal(t, "log.testPanic", le.Func)
+		assert.Contains(t, strings.SplitN(le.Trace, eol, 2)[0], "logfatal_test.go:", le.Trace)
+		if assert.NotNil(t, le.Error) {
+			assert.Equal(t, "assignment to entry in nil map", le.Error.Message)
+		}
+	}
+}
+
+func TestRecoverRepanic(t *testing.T) {
+	mw := NewMemoryWriter(10)
+	log := NewLog()
+	log.SetWriter(mw)
+
+	err := errors.New("oops")
+	assert.PanicsWithValue(t, err, func() {
+		defer log.Recover(true)
+		panic(err)
+	})
+
+	les := mw.Snapshot()
+	if assert.Equal(t, 1, len(les)) {
+		assert.Equal(t, "panic: oops", les[0].Msg)
+		assert.Equal(t, "log.TestRecoverRepanic.func1", les[0].Func)
+	}
+}
+
+func TestRecoverDefault(t *testing.T) {
+	mw := NewMemoryWriter(10)
+	log := Default()
+	log.SetWriter(mw)
+	defer log.SetWriter(nil)
+
+	func() {
+		defer Recover()
+		panic("default")
+	}()
+
+	assert.Equal(t, []string{"panic: default"}, memoryMsgs(mw.Snapshot()))
+}
+
+func TestCloseOnSignal(t *testing.T) {
+	defer func() { osExit = os.Exit }()
+
+	code := make(chan int, 1)
+	osExit = func(c int) {
+		code <- c
+	}
+
+	bw := &testBatchWriter{}
+	log := NewLog()
+	log.SetWriter(bw)
+	log.Async(100)
+	log.Info("info")
+
+	sc := make(chan os.Signal, 1)
+	go log.closeOnSignal(sc, make(chan struct{}))
+	sc <- syscall.SIGTERM
+
+	assert.Equal(t, 128+int(syscall.SIGTERM), <-code)
+	assert.Equal(t, []string{"info"}, bw.msgs)
+	assert.True(t, bw.closed)
+
+	// stop
+	stop := NewLog().CloseOnSignal()
+	stop()
+	stop()
+}
@@ -1,9 +1,14 @@
 package log
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pandafw/pango/str"
+	"github.com/pandafw/pango/str/wildcard"
 )
 
 // Filter log filter
@@ -26,6 +31,67 @@ func NewLevelFilter(lvl Level) *LevelFilter {
 	return &LevelFilter{Level: lvl}
 }
 
+// LevelRangeFilter log level range filter.
+// It accepts the events which level is between Highest and Lowest severity inclusively.
+type LevelRangeFilter struct {
+	Highest Level // the highest severity, e.g. LevelError
+	Lowest  Level // the lowest severity, e.g. LevelDebug
+}
+
+// Reject filter event by level range
+func (lrf *LevelRangeFilter) Reject(le *Event) bool {
+	return le.Level < lrf.Highest || le.Level > lrf.Lowest
+}
+
+// NewLevelRangeFilter create a level range filter, the order of the levels 'a' and 'b' does not matter.
+func NewLevelRangeFilter(a, b Level) *LevelRangeFilter {
+	if a > b {
+		a, b = b, a
+	}
+	return &LevelRangeFilter{Highest: a, Lowest: b}
+}
+
+// ExactLevelFilter exact log level filter.
+// It accepts the events which level is one of Levels.
+type ExactLevelFilter struct {
+	Levels []Level
+}
+
+// Reject filter event by exact levels
+func (elf *ExactLevelFilter) Reject(le *Event) bool {
+	for _, lvl := range elf.Levels {
+		if lvl == le.Level {
+			return false
+		}
+	}
+	return true
+}
+
+// NewExactLevelFilter create a exact level filter
+func NewExactLevelFilter(lvls ...Level) *ExactLevelFilter {
+	return &ExactLevelFilter{Levels: lvls}
+}
+
+// LoggerFilter logger name wildcard filter.
+// It accepts the events which logger name matches the Pattern ('*' and '?' wildcards are supported).
+type LoggerFilter struct {
+	Pattern string
+}
+
+// Reject filter event by logger name pattern
+func (lf *LoggerFilter) Reject(le *Event) bool {
+	name := ""
+	if le.Logger != nil {
+		name = le.Logger.GetName()
+	}
+	return !wildcard.Match(lf.Pattern, name)
+}
+
+// NewLoggerFilter create a logger name wildcard filter
+func NewLoggerFilter(pattern string) *LoggerFilter {
+	return &LoggerFilter{Pattern: pattern}
+}
+
 // NameFilter logger name filter
 type NameFilter struct {
 	Name string
@@ -76,6 +142,242 @@ func NewMultiFilter(fs ...Filter) *MultiFilter {
 	return &MultiFilter{Filters: fs}
 }
 
+// max count of the counter keys of the RateFilter/SampleFilter
+const maxFilterKeys = 10000
+
+// filterKey get the counter key of the event (logger name + level [+ message]).
+// The format string is used as the message of the event logged by Logf(), Errorf(), ...
+// so the messages formatted with different arguments share the same counter.
+func filterKey(le *Event, byMsg bool) string {
+	name := ""
+	if le.Logger != nil {
+		name = le.Logger.GetName()
+	}
+
+	key := name + "\x00" + le.Level.Prefix()
+	if byMsg {
+		msg := le.Format
+		if msg == "" {
+			msg = le.Msg
+		}
+		key += "\x00" + msg
+	}
+	return key
+}
+
+// filterCounter event counter of a key
+type filterCounter struct {
+	start time.Time
+	count int
+}
+
+// RateFilter rate limit filter.
+// It allows max Limit events per Interval for each logger+level (or logger+level+message if ByMsg is true).
+type RateFilter struct {
+	Limit    int
+	Interval time.Duration
+	ByMsg    bool
+
+	mutex    sync.Mutex
+	counters map[string]*filterCounter
+}
+
+// NewRateFilter create a rate limit filter, the limit and the interval must be positive
+func NewRateFilter(limit int, interval time.Duration, byMsg bool) (*RateFilter, error) {
+	if limit <= 0 || interval <= 0 {
+		return nil, fmt.Errorf("Invalid rate limit %d/%v", limit, interval)
+	}
+	return &RateFilter{Limit: limit, Interval: interval, ByMsg: byMsg}, nil
+}
+
+// Reject filter event by rate limit
+func (rf *RateFilter) Reject(le *Event) bool {
+	rf.mutex.Lock()
+	defer rf.mutex.Unlock()
+
+	if rf.counters == nil {
+		rf.counters = make(map[string]*filterCounter)
+	}
+
+	key := filterKey(le, rf.ByMsg)
+	fc, ok := rf.counters[key]
+	if !ok {
+		if len(rf.counters) >= maxFilterKeys {
+			rf.expire(le.When)
+		}
+		fc = &filterCounter{start: le.When}
+		rf.counters[key] = fc
+	} else if le.When.Sub(fc.start) >= rf.Interval {
+		fc.start = le.When
+		fc.count = 0
+	}
+
+	fc.count++
+	return fc.count > rf.Limit
+}
+
+// expire remove the expired counters, clear all counters if there is no expired counter
+func (rf *RateFilter) expire(tm time.Time) {
+	for k, fc := range rf.counters {
+		if tm.Sub(fc.start) >= rf.Interval {
+			delete(rf.counters, k)
+		}
+	}
+	if len(rf.counters) >= maxFilterKeys {
+		rf.counters = make(map[string]*filterCounter)
+	}
+}
+
+// SampleFilter sampling filter.
+// It allows the first First events, and then every Every-th event thereafter,
+// for each logger+level (or logger+level+message if ByMsg is true).
+// The counters are reset at every Interval (0: never reset).
+type SampleFilter struct {
+	First    int
+	Every    int
+	Interval time.Duration
+	ByMsg    bool
+
+	mutex    sync.Mutex
+	counters map[string]*filterCounter
+}
+
+// NewSampleFilter create a sampling filter
+func NewSampleFilter(first, every int, interval time.Duration, byMsg bool) *SampleFilter {
+	return &SampleFilter{First: first, Every: every, Interval: interval, ByMsg: byMsg}
+}
+
+// Reject filter event by sampling
+func (sf *SampleFilter) Reject(le *Event) bool {
+	sf.mutex.Lock()
+	defer sf.mutex.Unlock()
+
+	if sf.counters == nil {
+		sf.counters = make(map[string]*filterCounter)
+	}
+
+	key := filterKey(le, sf.ByMsg)
+	fc, ok := sf.counters[key]
+	if !ok {
+		if len(sf.counters) >= maxFilterKeys {
+			sf.counters = make(map[string]*filterCounter)
+		}
+		fc = &filterCounter{start: le.When}
+		sf.counters[key] = fc
+	} else if sf.Interval > 0 && le.When.Sub(fc.start) >= sf.Interval {
+		fc.start = le.When
+		fc.count = 0
+	}
+
+	fc.count++
+	if fc.count <= sf.First {
+		return false
+	}
+	if sf.Every <= 0 {
+		return true
+	}
+	return (fc.count-sf.First)%sf.Every != 0
+}
+
+// parseFilterInterval parse the interval of the rate/sample filter
+// s: second, m: minute, h: hour, or a duration string (10s)
+func parseFilterInterval(s string) (time.Duration, error) {
+	switch s {
+	case "s":
+		return time.Second, nil
+	case "m":
+		return time.Minute, nil
+	case "h":
+		return time.Hour, nil
+	default:
+		return time.ParseDuration(s)
+	}
+}
+
+// parseLevelsFilter parse the levels filter configuration "min-max" or "lvl1,lvl2,..."
+func parseLevelsFilter(s string) (Filter, error) {
+	if ss := strings.Split(s, "-"); len(ss) == 2 {
+		min, max := ParseLevel(ss[0]), ParseLevel(ss[1])
+		if min == LevelNone || max == LevelNone {
+			return nil, fmt.Errorf("Invalid levels filter %q", s)
+		}
+		return NewLevelRangeFilter(min, max), nil
+	}
+
+	var lvls []Level
+	for _, l := range strings.Split(s, ",") {
+		lvl := ParseLevel(l)
+		if lvl == LevelNone {
+			return nil, fmt.Errorf("Invalid levels filter %q", s)
+		}
+		lvls = append(lvls, lvl)
+	}
+	return NewExactLevelFilter(lvls...), nil
+}
+
+// parseRateFilter parse the rate filter configuration "N/interval[/msg]"
+func parseRateFilter(s string) (*RateFilter, error) {
+	ss := strings.Split(s, "/")
+	if len(ss) < 2 || len(ss) > 3 || (len(ss) == 3 && ss[2] != "msg") {
+		return nil, fmt.Errorf("Invalid rate filter %q", s)
+	}
+
+	n, err := strconv.Atoi(ss[0])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid rate filter %q: %v", s, err)
+	}
+
+	d, err := parseFilterInterval(ss[1])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid rate filter %q: %v", s, err)
+	}
+
+	rf, err := NewRateFilter(n, d, len(ss) == 3)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid rate filter %q: %v", s, err)
+	}
+	return rf, nil
+}
+
+// parseSampleFilter parse the sample filter configuration "first/every[/interval][/msg]"
+func parseSampleFilter(s string) (*SampleFilter, error) {
+	ss := strings.Split(s, "/")
+
+	msg := false
+	if len(ss) > 2 && ss[len(ss)-1] == "msg" {
+		msg = true
+		ss = ss[:len(ss)-1]
+	}
+
+	if len(ss) < 2 || len(ss) > 3 {
+		return nil, fmt.Errorf("Invalid sample filter %q", s)
+	}
+
+	first, err := strconv.Atoi(ss[0])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid sample filter %q: %v", s, err)
+	}
+
+	every, err := strconv.Atoi(ss[1])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid sample filter %q: %v", s, err)
+	}
+
+	var d time.Duration
+	if len(ss) > 2 {
+		d, err = parseFilterInterval(ss[2])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid sample filter %q: %v", s, err)
+		}
+	}
+
+	if first < 0 || every < 0 || d < 0 {
+		return nil, fmt.Errorf("Invalid sample filter %q", s)
+	}
+
+	return NewSampleFilter(first, every, d, msg), nil
+}
+
 // FilterCreator filter create function
 type FilterCreator func(s string) Filter
 
@@ -94,7 +396,8 @@ func CreateFilter(name string, conf string) Filter {
 	return nil
 }
 
-// NewLogFilter create a log filter by the configuration string 'c'
+// NewLogFilter create a log filter by the configuration string 'c', the invalid filters are ignored.
+// Use ParseLogFilter() to check the configuration string.
 func NewLogFilter(c string) Filter {
 	fs := []Filter{}
 	ss := strings.Split(c, " ")
@@ -108,6 +411,37 @@ func NewLogFilter(c string) Filter {
 		}
 	}
 
+	return joinFilters(fs)
+}
+
+// ParseLogFilter create a log filter by the configuration string 'c',
+// return an error if one of the filters is invalid.
+// the filters are separated by space, example: "level:error rate:10/m"
+// name:xxx - logger name filter (name:!xxx - not equal)
+// level:xxx - log level filter
+// levels:min-max - log level range filter, levels:xxx,yyy - exact log levels filter
+// logger:pattern - logger name wildcard filter ('*' and '?' are supported)
+// rate:N/interval[/msg] - max N events per interval (s, m, h or duration string 10s) for each logger+level[+message]
+// sample:first/every[/interval][/msg] - first N events then every M-th event for each logger+level[+message], the counters are reset at every interval
+func ParseLogFilter(c string) (Filter, error) {
+	fs := []Filter{}
+	for _, s := range strings.Fields(c) {
+		cs := strings.Split(s, ":")
+		if len(cs) != 2 {
+			return nil, fmt.Errorf("Invalid filter %q", s)
+		}
+
+		f := CreateFilter(cs[0], cs[1])
+		if f == nil {
+			return nil, fmt.Errorf("Invalid filter %q", s)
+		}
+		fs = append(fs, f)
+	}
+
+	return joinFilters(fs), nil
+}
+
+func joinFilters(fs []Filter) Filter {
 	if len(fs) < 1 {
 		return nil
 	}
@@ -127,4 +461,28 @@ func init() {
 	RegisterFilter("level", func(s string) Filter {
 		return NewLevelFilter(ParseLevel(s))
 	})
+	RegisterFilter("levels", func(s string) Filter {
+		lf, err := parseLevelsFilter(s)
+		if err != nil {
+			return nil
+		}
+		return lf
+	})
+	RegisterFilter("logger", func(s string) Filter {
+		return NewLoggerFilter(s)
+	})
+	RegisterFilter("rate", func(s string) Filter {
+		rf, err := parseRateFilter(s)
+		if err != nil {
+			return nil
+		}
+		return rf
+	})
+	RegisterFilter("sample", func(s string) Filter {
+		sf, err := parseSampleFilter(s)
+		if err != nil {
+			return nil
+		}
+		return sf
+	})
 }
@@ -0,0 +1,209 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testFilterRejects(f Filter, le *Event, n int, d time.Duration) []bool {
+	rs := make([]bool, n)
+	for i := 0; i < n; i++ {
+		rs[i] = f.Reject(le)
+		le.When = le.When.Add(d)
+	}
+	return rs
+}
+
+func TestRateFilter(t *testing.T) {
+	f := NewLogFilter("rate:2/s")
+	rf, ok := f.(*RateFilter)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, 2, rf.Limit)
+	assert.Equal(t, time.Second, rf.Interval)
+	assert.False(t, rf.ByMsg)
+
+	lg := NewLog().GetLogger("a")
+	le := &Event{Logger: lg, Level: LevelError, Msg: "1", When: time.Unix(0, 0)}
+	assert.Equal(t, []bool{false, false, true, true, false, false, true, true}, testFilterRejects(f, le, 8, time.Millisecond*300))
+
+	// other level
+	le = &Event{Logger: lg, Level: LevelWarn, Msg: "1", When: time.Unix(0, 0)}
+	assert.Equal(t, []bool{false, false, true}, testFilterRejects(f, le, 3, 0))
+
+	// other logger
+	le = &Event{Logger: NewLog().GetLogger("b"), Level: LevelError, Msg: "1", When: time.Unix(0, 0)}
+	assert.Equal(t, []bool{false, false, true}, testFilterRejects(f, le, 3, 0))
+}
+
+func TestRateFilterByMsg(t *testing.T) {
+	f := NewLogFilter("rate:1/10s/msg")
+	rf, ok := f.(*RateFilter)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, time.Second*10, rf.Interval)
+	assert.True(t, rf.ByMsg)
+
+	lg := NewLog().GetLogger("a")
+	le := &Event{Logger: lg, Level: LevelError, Msg: "1", When: time.Unix(0, 0)}
+	assert.Equal(t, []bool{false, true}, testFilterRejects(f, le, 2, 0))
+
+	le = &Event{Logger: lg, Level: LevelError, Msg: "2", When: time.Unix(0, 0)}
+	assert.Equal(t, []bool{false, true}, testFilterRejects(f, le, 2, 0))
+
+	// same format string with different arguments
+	le = &Event{Logger: lg, Level: LevelError, Msg: "user 1", Format: "user %d", When: time.Unix(0, 0)}
+	assert.False(t, f.Reject(le))
+	le = &Event{Logger: lg, Level: LevelError, Msg: "user 2", Format: "user %d", When: time.Unix(0, 0)}
+	assert.True(t, f.Reject(le))
+}
+
+func TestRateFilterByFormat(t *testing.T) {
+	mw := NewMemoryWriter(10)
+	assert.Nil(t, mw.SetFilter("rate:1/m/msg"))
+
+	log := NewLog()
+	log.SetWriter(mw)
+	log.Errorf("user %d", 1)
+	log.Errorf("user %d", 2)
+	log.Errorf("item %d", 1)
+	log.Close()
+
+	assert.Equal(t, []string{"user 1", "item 1"}, memoryMsgs(mw.Snapshot()))
+}
+
+func TestSampleFilter(t *testing.T) {
+	f := NewLogFilter("sample:2/3")
+	sf, ok := f.(*SampleFilter)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, 2, sf.First)
+	assert.Equal(t, 3, sf.Every)
+	assert.Equal(t, time.Duration(0), sf.Interval)
+
+	le := &Event{Logger: NewLog().GetLogger("a"), Level: LevelError, When: time.Unix(0, 0)}
+	assert.Equal(t, []bool{false, false, true, true, false, true, true, false}, testFilterRejects(f, le, 8, time.Hour))
+}
+
+func TestSampleFilterInterval(t *testing.T) {
+	f := NewLogFilter("sample:1/0/m/msg")
+	sf, ok := f.(*SampleFilter)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, time.Minute, sf.Interval)
+	assert.True(t, sf.ByMsg)
+
+	le := &Event{Logger: NewLog().GetLogger("a"), Level: LevelError, When: time.Unix(0, 0)}
+	assert.Equal(t, []bool{false, true, true, false, true, true}, testFilterRejects(f, le, 6, time.Second*20))
+}
+
+func TestNewLogFilterMulti(t *testing.T) {
+	f, err := ParseLogFilter("level:error rate:100/s sample:1/2")
+	if !assert.Nil(t, err) {
+		return
+	}
+	mf, ok := f.(*MultiFilter)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, 3, len(mf.Filters))
+}
+
+func TestParseLogFilterInvalid(t *testing.T) {
+	cs := []string{
+		"level:error sample:1/x",
+		"rate:x/s",
+		"rate:1/x",
+		"rate:1/s/x",
+		"rate:0/s",
+		"rate:-1/s",
+		"rate:1/0s",
+		"rate:1/-1s",
+		"sample:-1/2",
+		"sample:1/2/-1s",
+		"sample:1",
+		"levels:x-debug",
+		"unknown:x",
+		"level",
+	}
+
+	for i, c := range cs {
+		f, err := ParseLogFilter(c)
+		assert.Nil(t, f, "[%d] %s", i, c)
+		assert.NotNil(t, err, "[%d] %s", i, c)
+	}
+
+	f, err := ParseLogFilter("")
+	assert.Nil(t, f)
+	assert.Nil(t, err)
+
+	assert.NotNil(t, (&FileWriter{}).SetFilter("rate:1/x"))
+}
+
+func testFilterLevels(f Filter) []bool {
+	rs := []bool{}
+	for lvl := LevelFatal; lvl <= LevelTrace; lvl++ {
+		rs = append(rs, f.Reject(&Event{Level: lvl}))
+	}
+	return rs
+}
+
+func TestLevelRangeFilter(t *testing.T) {
+	f := NewLogFilter("levels:debug-warn")
+	lrf, ok := f.(*LevelRangeFilter)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, LevelWarn, lrf.Highest)
+	assert.Equal(t, LevelDebug, lrf.Lowest)
+
+	// F E W I D T
+	assert.Equal(t, []bool{true, true, false, false, false, true}, testFilterLevels(f))
+	assert.Equal(t, testFilterLevels(f), testFilterLevels(NewLogFilter("levels:warn-debug")))
+}
+
+func TestExactLevelFilter(t *testing.T) {
+	f := NewLogFilter("levels:warn")
+	_, ok := f.(*ExactLevelFilter)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	// F E W I D T
+	assert.Equal(t, []bool{true, true, false, true, true, true}, testFilterLevels(f))
+	assert.Equal(t, []bool{false, true, true, false, true, true}, testFilterLevels(NewLogFilter("levels:fatal,info")))
+
+	assert.Nil(t, NewLogFilter("levels:x"))
+	assert.Nil(t, NewLogFilter("levels:x-debug"))
+}
+
+func TestLoggerFilter(t *testing.T) {
+	log := NewLog()
+	f := NewLogFilter("logger:web.*")
+
+	assert.False(t, f.Reject(&Event{Logger: log.GetLogger("web.api")}))
+	assert.True(t, f.Reject(&Event{Logger: log.GetLogger("web")}))
+	assert.True(t, f.Reject(&Event{Logger: log.GetLogger("sql")}))
+
+	f = NewLogFilter("logger:sql?")
+	assert.False(t, f.Reject(&Event{Logger: log.GetLogger("sql1")}))
+	assert.True(t, f.Reject(&Event{Logger: log.GetLogger("sql12")}))
+}
+
+func TestNewRateFilterInvalid(t *testing.T) {
+	_, err := NewRateFilter(0, time.Second, false)
+	assert.NotNil(t, err)
+
+	_, err = NewRateFilter(1, 0, false)
+	assert.NotNil(t, err)
+
+	rf, err := NewRateFilter(1, time.Second, false)
+	assert.Nil(t, err)
+	assert.NotNil(t, rf)
+}
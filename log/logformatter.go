@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/pandafw/pango/iox"
 )
@@ -20,6 +22,13 @@ type Formatter interface {
 	Format(le *Event) string
 }
 
+// ColorFormatter a formatter which supports the color directives
+type ColorFormatter interface {
+	Formatter
+	IsColored() bool
+	WriteColor(bb *bytes.Buffer, le *Event)
+}
+
 // TextFmtSubject subject log format "[%l] %m"
 var TextFmtSubject = newTextFormatter("[%l] %m")
 
@@ -32,9 +41,15 @@ var TextFmtDefault = newTextFormatter("%t %l{-5s} %S:%L %F() - %m%n%T")
 // JSONFmtDefault default log format `{"when": %t, "level": %l, "file": %S, "line": %L, "func": %F, "msg": %m, "trace": %T}%n`
 var JSONFmtDefault = newJSONFormatter(`{"when": %t, "level": %l, "file": %S, "line": %L, "func": %F, "msg": %m, "trace": %T}%n`)
 
-// NewLogFormatter create a text or json formatter
+// LogfmtFmtDefault default logfmt format "time=%t level=%l file=%S line=%L func=%F msg=%m%X%W%n"
+var LogfmtFmtDefault = newLogfmtFormatter("time=%t level=%l file=%S line=%L func=%F msg=%m%X%W%n")
+
+// NewLogFormatter create a text, json, logfmt, gelf or ecs formatter
 // text:[%p] %m%n -> TextFormatter
 // json:{"level":%l, "msg": %m}%n  -> JSONFormatter
+// logfmt:level=%l msg=%m%n -> LogfmtFormatter
+// gelf:hostname -> GELFFormatter (the hostname is optional, default: os.Hostname())
+// ecs: -> ECSFormatter
 func NewLogFormatter(format string) Formatter {
 	if strings.HasPrefix(format, "text:") {
 		return NewTextFormatter(format[5:])
@@ -42,6 +57,15 @@ func NewLogFormatter(format string) Formatter {
 	if strings.HasPrefix(format, "json:") {
 		return NewJSONFormatter(format[5:])
 	}
+	if strings.HasPrefix(format, "logfmt:") {
+		return NewLogfmtFormatter(format[7:])
+	}
+	if strings.HasPrefix(format, "gelf:") {
+		return NewGELFFormatter(format[5:])
+	}
+	if strings.HasPrefix(format, "ecs:") {
+		return NewECSFormatter()
+	}
 	return NewTextFormatter(format)
 }
 
@@ -53,12 +77,18 @@ func NewLogFormatter(format string) Formatter {
 // %l{format}: log level string
 // %x{key}: logger property
 // %X{=| }: logger properties (operator|separator)
+// %w{key}: structured field
+// %W{=| }: structured fields (operator|separator)
 // %S: caller source file name (!!SLOW!!)
 // %L: caller source line number (!!SLOW!!)
 // %F: caller function name (!!SLOW!!)
 // %T: caller stack trace (!!SLOW!!)
+// %e{part}: error message, {part}: type, causes, stack (the stack trace where the error was created)
+// %E: error type, message, causes and stack trace
 // %m: message
 // %n: EOL(Windows: "\r\n", Other: "\n")
+// %K{color}: color directive (red, green, yellow, blue, magenta, cyan, white, gray, level, reset),
+// only used by the StreamWriter which outputs to a color terminal, %K is the same as %K{reset}
 func NewTextFormatter(format string) *TextFormatter {
 	switch format {
 	case "DEFAULT":
@@ -86,10 +116,14 @@ func newTextFormatter(format string) *TextFormatter {
 // %l{format}: log level string
 // %x{key}: logger property
 // %X: logger properties (json format)
+// %w{key}: structured field
+// %W: structured fields (json format)
 // %S: caller source file name (!!SLOW!!)
 // %L: caller source line number (!!SLOW!!)
 // %F: caller function name (!!SLOW!!)
 // %T: caller stack trace (!!SLOW!!)
+// %e{part}: error message, {part}: type, causes (json array), stack (the stack trace where the error was created)
+// %E: error info (json format)
 // %m: message
 // %n: EOL(Windows: "\r\n", Other: "\n")
 func NewJSONFormatter(format string) *JSONFormatter {
@@ -107,9 +141,43 @@ func newJSONFormatter(format string) *JSONFormatter {
 	return jf
 }
 
+// NewLogfmtFormatter create a Logfmt Formatter instance
+// Logfmt Format (the values are quoted if necessary)
+// %t{format}: time, if {format} is omitted, '2006-01-02T15:04:05.000' will be used
+// %c{format}: logger name
+// %p{format}: log level prefix
+// %l{format}: log level string
+// %x{key}: logger property
+// %X: logger properties (" key=value" pairs)
+// %w{key}: structured field
+// %W: structured fields (" key=value" pairs)
+// %S: caller source file name (!!SLOW!!)
+// %L: caller source line number (!!SLOW!!)
+// %F: caller function name (!!SLOW!!)
+// %T: caller stack trace (!!SLOW!!)
+// %e{part}: error message, {part}: type, causes, stack (the stack trace where the error was created)
+// %E: error type, message, causes and stack trace
+// %m: message
+// %n: EOL(Windows: "\r\n", Other: "\n")
+func NewLogfmtFormatter(format string) *LogfmtFormatter {
+	switch format {
+	case "", "DEFAULT":
+		return LogfmtFmtDefault
+	default:
+		return newLogfmtFormatter(format)
+	}
+}
+
+func newLogfmtFormatter(format string) *LogfmtFormatter {
+	lf := &LogfmtFormatter{}
+	lf.Init(format)
+	return lf
+}
+
 // TextFormatter text formatter
 type TextFormatter struct {
-	fmts []fmtfunc
+	fmts  []fmtfunc
+	cfmts []fmtfunc // fmts with the color directives, nil if no color directive
 }
 
 // Format format the log event to the buffer 'bb'
@@ -122,6 +190,20 @@ func (tf *TextFormatter) Format(le *Event) string {
 	return format(le, tf.fmts)
 }
 
+// IsColored return true if the format contains color directives
+func (tf *TextFormatter) IsColored() bool {
+	return tf.cfmts != nil
+}
+
+// WriteColor format the log event with the color directives to the buffer 'bb'
+func (tf *TextFormatter) WriteColor(bb *bytes.Buffer, le *Event) {
+	if tf.cfmts == nil {
+		write(bb, le, tf.fmts)
+		return
+	}
+	write(bb, le, tf.cfmts)
+}
+
 func getFormatOption(format string, i *int) string {
 	p := format[*i+1:]
 	if len(p) > 0 && p[0] == '{' {
@@ -137,6 +219,8 @@ func getFormatOption(format string, i *int) string {
 // Init initialize the text formatter
 func (tf *TextFormatter) Init(format string) {
 	fmts := make([]fmtfunc, 0, 10)
+	cfmts := make([]fmtfunc, 0, 10)
+	colored := false
 
 	s := 0
 	for i := 0; i < len(format); i++ {
@@ -148,6 +232,7 @@ func (tf *TextFormatter) Init(format string) {
 		// string
 		if s < i {
 			fmts = append(fmts, strfmtc(format[s:i]))
+			cfmts = append(cfmts, strfmtc(format[s:i]))
 		}
 
 		i++
@@ -197,6 +282,17 @@ func (tf *TextFormatter) Init(format string) {
 				p = "=| "
 			}
 			fmt = propsfmtc(p)
+		case 'w':
+			p := getFormatOption(format, &i)
+			if p != "" {
+				fmt = fieldfmtc(p)
+			}
+		case 'W':
+			p := getFormatOption(format, &i)
+			if p == "" {
+				p = "=| "
+			}
+			fmt = fieldsfmtc(p)
 		case 'S':
 			fmt = filefmt
 		case 'L':
@@ -205,23 +301,39 @@ func (tf *TextFormatter) Init(format string) {
 			fmt = funcfmt
 		case 'T':
 			fmt = tracefmt
+		case 'e':
+			fmt = errfmtc(getFormatOption(format, &i))
+		case 'E':
+			fmt = errsfmt
 		case 'm':
 			fmt = msgfmt
 		case 'n':
 			fmt = eolfmt
+		case 'K':
+			p := getFormatOption(format, &i)
+			if cf := colorfmtc(p); cf != nil {
+				cfmts = append(cfmts, cf)
+				colored = true
+			}
+			s = i + 1
 		}
 
 		if fmt != nil {
 			fmts = append(fmts, fmt)
+			cfmts = append(cfmts, fmt)
 			s = i + 1
 		}
 	}
 
 	if s < len(format) {
 		fmts = append(fmts, strfmtc(format[s:]))
+		cfmts = append(cfmts, strfmtc(format[s:]))
 	}
 
 	tf.fmts = fmts
+	if colored {
+		tf.cfmts = cfmts
+	}
 }
 
 // JSONFormatter json formatter
@@ -301,6 +413,13 @@ func (jf *JSONFormatter) Init(format string) {
 			}
 		case 'X':
 			fmt = jpropsfmt
+		case 'w':
+			p := getFormatOption(format, &i)
+			if p != "" {
+				fmt = jfieldfmtc(p)
+			}
+		case 'W':
+			fmt = jfieldsfmt
 		case 'S':
 			fmt = quotefmtc(filefmt)
 		case 'L':
@@ -309,6 +428,15 @@ func (jf *JSONFormatter) Init(format string) {
 			fmt = quotefmtc(funcfmt)
 		case 'T':
 			fmt = quotefmtc(tracefmt)
+		case 'e':
+			p := getFormatOption(format, &i)
+			if p == "causes" {
+				fmt = jerrcausesfmt
+			} else {
+				fmt = quotefmtc(errfmtc(p))
+			}
+		case 'E':
+			fmt = jerrsfmt
 		case 'm':
 			fmt = quotefmtc(msgfmt)
 		case 'n':
@@ -327,6 +455,120 @@ func (jf *JSONFormatter) Init(format string) {
 	jf.fmts = fmts
 }
 
+// LogfmtFormatter logfmt formatter
+type LogfmtFormatter struct {
+	fmts []fmtfunc
+}
+
+// Write format the log event as a logfmt string to the buffer 'bb'
+func (lf *LogfmtFormatter) Write(bb *bytes.Buffer, le *Event) {
+	write(bb, le, lf.fmts)
+}
+
+// Format format the log event to a logfmt string
+func (lf *LogfmtFormatter) Format(le *Event) string {
+	return format(le, lf.fmts)
+}
+
+// Init initialize the logfmt formatter
+func (lf *LogfmtFormatter) Init(format string) {
+	fmts := make([]fmtfunc, 0, 10)
+
+	s := 0
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			continue
+		}
+
+		// string
+		if s < i {
+			fmts = append(fmts, strfmtc(format[s:i]))
+		}
+
+		i++
+		s = i
+		if i >= len(format) {
+			break
+		}
+
+		// symbol
+		var fmt fmtfunc
+		switch format[i] {
+		case 't':
+			p := getFormatOption(format, &i)
+			if p == "" {
+				p = defaultTimeFormat
+			}
+			fmt = lquotefmtc(timefmtc(p))
+		case 'c':
+			p := getFormatOption(format, &i)
+			if p == "" {
+				fmt = namefmt
+			} else {
+				fmt = namefmtc("%" + p)
+			}
+			fmt = lquotefmtc(fmt)
+		case 'p':
+			p := getFormatOption(format, &i)
+			if p == "" {
+				fmt = lvlpfmt
+			} else {
+				fmt = lvlpfmtc("%" + p)
+			}
+			fmt = lquotefmtc(fmt)
+		case 'l':
+			p := getFormatOption(format, &i)
+			if p == "" {
+				fmt = lvlsfmt
+			} else {
+				fmt = lvlsfmtc("%" + p)
+			}
+			fmt = lquotefmtc(fmt)
+		case 'x':
+			p := getFormatOption(format, &i)
+			if p != "" {
+				fmt = lquotefmtc(propfmtc(p))
+			}
+		case 'X':
+			fmt = lpropsfmt
+		case 'w':
+			p := getFormatOption(format, &i)
+			if p != "" {
+				fmt = lquotefmtc(fieldfmtc(p))
+			}
+		case 'W':
+			fmt = lfieldsfmt
+		case 'S':
+			fmt = lquotefmtc(filefmt)
+		case 'L':
+			fmt = linefmt
+		case 'F':
+			fmt = lquotefmtc(funcfmt)
+		case 'T':
+			fmt = lquotefmtc(tracefmt)
+		case 'e':
+			fmt = lquotefmtc(errfmtc(getFormatOption(format, &i)))
+		case 'E':
+			fmt = lquotefmtc(errsfmt)
+		case 'm':
+			fmt = lquotefmtc(msgfmt)
+		case 'n':
+			fmt = eolfmt
+		}
+
+		if fmt != nil {
+			fmts = append(fmts, fmt)
+			s = i + 1
+		}
+	}
+
+	if s < len(format) {
+		fmts = append(fmts, strfmtc(format[s:]))
+	}
+	lf.fmts = fmts
+}
+
 //-------------------------------------------------
 
 type fmtfunc func(le *Event) string
@@ -353,6 +595,42 @@ func quotefmtc(ff fmtfunc) fmtfunc {
 	}
 }
 
+func colorfmtc(c string) fmtfunc {
+	var s string
+	switch strings.ToLower(c) {
+	case "level":
+		return lvlcolorfmt
+	case "", "reset":
+		s = iox.ConsoleColor.Reset
+	case "red":
+		s = iox.ConsoleColor.Red
+	case "green":
+		s = iox.ConsoleColor.Green
+	case "yellow":
+		s = iox.ConsoleColor.Yellow
+	case "blue":
+		s = iox.ConsoleColor.Blue
+	case "magenta":
+		s = iox.ConsoleColor.Magenta
+	case "cyan":
+		s = iox.ConsoleColor.Cyan
+	case "white":
+		s = iox.ConsoleColor.White
+	case "gray", "grey":
+		s = iox.ConsoleColor.Gray
+	default:
+		return nil
+	}
+	return strfmtc(s)
+}
+
+func lvlcolorfmt(le *Event) string {
+	if int(le.Level) < len(colors) {
+		return colors[le.Level]
+	}
+	return ""
+}
+
 func strfmtc(s string) fmtfunc {
 	return func(le *Event) string {
 		return s
@@ -434,6 +712,113 @@ func jpropsfmt(le *Event) string {
 	return string(b)
 }
 
+func fieldfmtc(key string) fmtfunc {
+	return func(le *Event) string {
+		return fmt.Sprint(le.Fields[key])
+	}
+}
+
+func fieldsfmtc(f string) fmtfunc {
+	ss := strings.Split(f, "|")
+	d := ss[0]
+	j := ""
+	if len(ss) > 1 {
+		j = ss[1]
+	}
+	return func(le *Event) string {
+		m := le.Fields
+		if len(m) == 0 {
+			return ""
+		}
+
+		ks := make([]string, 0, len(m))
+		for k := range m {
+			ks = append(ks, k)
+		}
+		sort.Strings(ks)
+
+		a := make([]string, len(ks))
+		for i, k := range ks {
+			a[i] = fmt.Sprintf("%s%s%v", k, d, m[k])
+		}
+		return strings.Join(a, j)
+	}
+}
+
+func jfieldfmtc(key string) fmtfunc {
+	return func(le *Event) string {
+		b, _ := json.Marshal(le.Fields[key])
+		return string(b)
+	}
+}
+
+func jfieldsfmt(le *Event) string {
+	if le.Fields == nil {
+		return "{}"
+	}
+	b, _ := json.Marshal(le.Fields)
+	return string(b)
+}
+
+// logfmtQuote quote the logfmt value if it is empty or contains space, '=', '"' or control characters
+func logfmtQuote(s string) string {
+	if s == "" {
+		return `""`
+	}
+	for _, c := range s {
+		if c <= ' ' || c == '=' || c == '"' || c == 0x7f || c == utf8.RuneError {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
+
+// logfmtKey replace the invalid characters of the logfmt key with '_'
+func logfmtKey(k string) string {
+	return strings.Map(func(c rune) rune {
+		if c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+			return '_'
+		}
+		return c
+	}, k)
+}
+
+// logfmtPairs format the map as sorted " key=value" pairs
+func logfmtPairs(m map[string]interface{}) string {
+	if len(m) == 0 {
+		return ""
+	}
+
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+
+	sb := &strings.Builder{}
+	for _, k := range ks {
+		sb.WriteByte(' ')
+		sb.WriteString(logfmtKey(k))
+		sb.WriteByte('=')
+		sb.WriteString(logfmtQuote(fmt.Sprint(m[k])))
+	}
+	return sb.String()
+}
+
+func lquotefmtc(ff fmtfunc) fmtfunc {
+	return func(le *Event) string {
+		return logfmtQuote(ff(le))
+	}
+}
+
+func lpropsfmt(le *Event) string {
+	return logfmtPairs(le.Logger.GetProps())
+}
+
+func lfieldsfmt(le *Event) string {
+	return logfmtPairs(le.Fields)
+}
+
 func funcfmt(le *Event) string {
 	return le.Func
 }
@@ -450,6 +835,51 @@ func tracefmt(le *Event) string {
 	return le.Trace
 }
 
+// errfmtc return the error info formatter by the option 'p':
+// "": message, "type": type, "causes": causes, "stack": the stack trace of the origin error
+func errfmtc(p string) fmtfunc {
+	return func(le *Event) string {
+		ei := le.Error
+		if ei == nil {
+			return ""
+		}
+
+		switch p {
+		case "type":
+			return ei.Type
+		case "causes":
+			return ei.CausesString()
+		case "stack":
+			return ei.Stack
+		default:
+			return ei.Message
+		}
+	}
+}
+
+func errsfmt(le *Event) string {
+	if le.Error == nil {
+		return ""
+	}
+	return le.Error.String()
+}
+
+func jerrcausesfmt(le *Event) string {
+	if le.Error == nil || len(le.Error.Causes) == 0 {
+		return "[]"
+	}
+	b, _ := json.Marshal(le.Error.Causes)
+	return string(b)
+}
+
+func jerrsfmt(le *Event) string {
+	if le.Error == nil {
+		return "null"
+	}
+	b, _ := json.Marshal(le.Error)
+	return string(b)
+}
+
 func msgfmt(le *Event) string {
 	return le.Msg
 }
@@ -1,12 +1,14 @@
 package log
 
 import (
+	"bytes"
 	"sort"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/pandafw/pango/iox"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -155,3 +157,66 @@ func TestNewLogFormatJSONDefault(t *testing.T) {
 	le.Caller(2, false)
 	assert.Equal(t, `{"when": "`+le.When.Format(defaultTimeFormat)+`", "level": "INFO", "file": "logformatter_test.go", "line": `+strconv.Itoa(le.Line)+`, "func": "log.TestNewLogFormatJSONDefault", "msg": "default", "trace": ""}`+eol, jf.Format(le))
 }
+
+func TestTextFormatField(t *testing.T) {
+	tf := NewTextFormatter("%w{a} %w{-}")
+	lg := NewLog().GetLogger("").With("a", "av")
+	le := newEvent(lg, LevelInfo, "field")
+	le.When = time.Time{}
+	assert.Equal(t, `av <nil>`, tf.Format(le))
+}
+
+func TestTextFormatFields(t *testing.T) {
+	tf := NewTextFormatter("%W{=|,} - %m")
+	lg := NewLog().GetLogger("").With("c", "cv", "a", "av").With("n", 11, "x")
+	le := newEvent(lg, LevelInfo, "fields")
+	le.When = time.Time{}
+	assert.Equal(t, `a=av,c=cv,n=11,x=<nil> - fields`, tf.Format(le))
+}
+
+func TestJSONFormatFields(t *testing.T) {
+	jf := NewJSONFormatter(`{"msg": %m, "a": %w{a}, "fields": %W}`)
+	lg := NewLog().With("b", 2, "a", "av")
+	le := newEvent(lg, LevelInfo, "fields")
+	assert.Equal(t, `{"msg": "fields", "a": "av", "fields": {"a":"av","b":2}}`, jf.Format(le))
+
+	le = newEvent(NewLog().GetLogger(""), LevelInfo, "none")
+	assert.Equal(t, `{"msg": "none", "a": null, "fields": {}}`, jf.Format(le))
+}
+
+func TestLogfmtFormat(t *testing.T) {
+	lf := NewLogFormatter("logfmt:time=%t level=%l logger=%c msg=%m%X%W%n")
+	lg := NewLog().GetLogger("app")
+	lg.SetProp("host", "h 1")
+	le := newEvent(lg.With("user", "a=b", "n", 1), LevelInfo, `say "hi"`)
+	le.When = time.Time{}
+	assert.Equal(t, `time=0001-01-01T00:00:00.000 level=INFO logger=app msg="say \"hi\"" host="h 1" n=1 user="a=b"`+eol, lf.Format(le))
+}
+
+func TestLogfmtFormatEmpty(t *testing.T) {
+	lf := NewLogfmtFormatter("msg=%m x=%x{x}%X")
+	le := newEvent(NewLog().GetLogger(""), LevelInfo, "")
+	assert.Equal(t, `msg="" x=<nil>`, lf.Format(le))
+}
+
+func TestNewLogFormatLogfmtDefault(t *testing.T) {
+	lf := NewLogFormatter("logfmt:")
+	le := newEvent(NewLog().GetLogger(""), LevelInfo, "default")
+	le.When = time.Time{}
+	le.Caller(2, false)
+	assert.Equal(t, `time=0001-01-01T00:00:00.000 level=INFO file=logformatter_test.go line=`+strconv.Itoa(le.Line)+` func=log.TestNewLogFormatLogfmtDefault msg=default`+eol, lf.Format(le))
+}
+
+func TestTextFormatColor(t *testing.T) {
+	tf := NewTextFormatter("%K{level}%l%K{}|%K{red}%m%K{invalid}%K")
+	le := newEvent(NewLog().GetLogger(""), LevelDebug, "color")
+
+	assert.True(t, tf.IsColored())
+	assert.Equal(t, "DEBUG|color", tf.Format(le))
+
+	bb := &bytes.Buffer{}
+	tf.WriteColor(bb, le)
+	assert.Equal(t, iox.ConsoleColor.White+"DEBUG"+iox.ConsoleColor.Reset+"|"+iox.ConsoleColor.Red+"color"+iox.ConsoleColor.Reset, bb.String())
+
+	assert.False(t, NewTextFormatter("%l %m").IsColored())
+}
@@ -1,6 +1,7 @@
 package log
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -16,6 +17,8 @@ type Logger interface {
 	SetProp(k string, v interface{})
 	GetProps() map[string]interface{}
 	SetProps(map[string]interface{})
+	GetFields() map[string]interface{}
+	With(kvs ...interface{}) Logger
 	IsLevelEnabled(lvl Level) bool
 	Log(lvl Level, v ...interface{})
 	Logf(lvl Level, f string, v ...interface{})
@@ -37,14 +40,22 @@ type Logger interface {
 	IsTraceEnabled() bool
 	Trace(v ...interface{})
 	Tracef(f string, v ...interface{})
+	LogCtx(ctx context.Context, lvl Level, v ...interface{})
+	FatalCtx(ctx context.Context, v ...interface{})
+	ErrorCtx(ctx context.Context, v ...interface{})
+	WarnCtx(ctx context.Context, v ...interface{})
+	InfoCtx(ctx context.Context, v ...interface{})
+	DebugCtx(ctx context.Context, v ...interface{})
+	TraceCtx(ctx context.Context, v ...interface{})
 }
 
 // logger logger interface implement
 type logger struct {
-	log   *Log
-	name  string
-	depth int
-	props map[string]interface{}
+	log    *Log
+	name   string
+	depth  int
+	props  map[string]interface{}
+	fields map[string]interface{}
 }
 
 // GetName return the logger's name
@@ -139,6 +150,23 @@ func (l *logger) SetProps(props map[string]interface{}) {
 	l.props = props
 }
 
+// GetFields get logger structured fields
+func (l *logger) GetFields() map[string]interface{} {
+	return l.fields
+}
+
+// With returns a child logger with the key/value pairs 'kvs' added to the structured fields
+// example: logger.With("user", uid, "req", rid).Info("...")
+func (l *logger) With(kvs ...interface{}) Logger {
+	return &logger{
+		log:    l.log,
+		name:   l.name,
+		depth:  l.depth,
+		props:  l.props,
+		fields: addFields(l.fields, kvs),
+	}
+}
+
 // GetFormatter get logger formatter
 func (l *logger) GetFormatter() Formatter {
 	return l.log.GetFormatter()
@@ -249,10 +277,46 @@ func (l *logger) Tracef(f string, v ...interface{}) {
 	l._logf(LevelTrace, f, v...)
 }
 
+// LogCtx log a message at specified level with the request-scoped fields of ctx.
+func (l *logger) LogCtx(ctx context.Context, lvl Level, v ...interface{}) {
+	l._logCtx(ctx, lvl, v...)
+}
+
+// FatalCtx log a message at fatal level with the request-scoped fields of ctx.
+func (l *logger) FatalCtx(ctx context.Context, v ...interface{}) {
+	l._logCtx(ctx, LevelFatal, v...)
+}
+
+// ErrorCtx log a message at error level with the request-scoped fields of ctx.
+func (l *logger) ErrorCtx(ctx context.Context, v ...interface{}) {
+	l._logCtx(ctx, LevelError, v...)
+}
+
+// WarnCtx log a message at warning level with the request-scoped fields of ctx.
+func (l *logger) WarnCtx(ctx context.Context, v ...interface{}) {
+	l._logCtx(ctx, LevelWarn, v...)
+}
+
+// InfoCtx log a message at info level with the request-scoped fields of ctx.
+func (l *logger) InfoCtx(ctx context.Context, v ...interface{}) {
+	l._logCtx(ctx, LevelInfo, v...)
+}
+
+// DebugCtx log a message at debug level with the request-scoped fields of ctx.
+func (l *logger) DebugCtx(ctx context.Context, v ...interface{}) {
+	l._logCtx(ctx, LevelDebug, v...)
+}
+
+// TraceCtx log a message at trace level with the request-scoped fields of ctx.
+func (l *logger) TraceCtx(ctx context.Context, v ...interface{}) {
+	l._logCtx(ctx, LevelTrace, v...)
+}
+
 func (l *logger) _log(lvl Level, v ...interface{}) {
 	if l.IsLevelEnabled(lvl) {
 		s := l._printv(v...)
 		le := newEvent(l, lvl, s)
+		le.Error = findErrorInfo(v)
 		l.log.submit(le)
 	}
 }
@@ -261,6 +325,18 @@ func (l *logger) _logf(lvl Level, f string, v ...interface{}) {
 	if l.IsLevelEnabled(lvl) {
 		s := l._printf(f, v...)
 		le := newEvent(l, lvl, s)
+		le.Format = f
+		le.Error = findErrorInfo(v)
+		l.log.submit(le)
+	}
+}
+
+func (l *logger) _logCtx(ctx context.Context, lvl Level, v ...interface{}) {
+	if l.IsLevelEnabled(lvl) {
+		s := l._printv(v...)
+		le := newEvent(l, lvl, s)
+		le.Error = findErrorInfo(v)
+		le.Fields = mergeFields(le.Fields, ContextFields(ctx))
 		l.log.submit(le)
 	}
 }
@@ -275,3 +351,25 @@ func (l *logger) _printv(v ...interface{}) string {
 func (l *logger) _printf(f string, v ...interface{}) string {
 	return fmt.Sprintf(f, v...)
 }
+
+// addFields copy the fields 'fs' and add the key/value pairs 'kvs' (copy on write for async)
+func addFields(fs map[string]interface{}, kvs []interface{}) map[string]interface{} {
+	nm := make(map[string]interface{}, len(fs)+(len(kvs)+1)/2)
+	for k, v := range fs {
+		nm[k] = v
+	}
+
+	for i := 0; i < len(kvs); i += 2 {
+		k, ok := kvs[i].(string)
+		if !ok {
+			k = fmt.Sprint(kvs[i])
+		}
+
+		var v interface{}
+		if i+1 < len(kvs) {
+			v = kvs[i+1]
+		}
+		nm[k] = v
+	}
+	return nm
+}
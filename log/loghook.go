@@ -0,0 +1,214 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Hook log event hook.
+// The hooks are called for each event before the event is written (sync mode) or enqueued (async mode),
+// in the goroutine which logs the event.
+// A hook can enrich (add fields), modify (e.g. redact the message) or drop (return false) the event.
+// The event's Fields map may be shared with the logger, use Event.AddFields() to add fields.
+type Hook interface {
+	Fire(le *Event) bool
+}
+
+// HookFunc the function implements the Hook interface
+type HookFunc func(le *Event) bool
+
+// Fire call the hook function
+func (hf HookFunc) Fire(le *Event) bool {
+	return hf(le)
+}
+
+// AddFields add the fields (key/value pairs) to the event, the Fields map is copied before it is modified
+func (le *Event) AddFields(kvs ...interface{}) {
+	le.Fields = addFields(le.Fields, kvs)
+}
+
+// FieldHook a hook adds the static field Key=Value to the event
+type FieldHook struct {
+	Key   string
+	Value interface{}
+}
+
+// Fire add the field to the event
+func (fh *FieldHook) Fire(le *Event) bool {
+	le.AddFields(fh.Key, fh.Value)
+	return true
+}
+
+// NewFieldHook create a hook which adds the static field 'key'='value' to the event
+func NewFieldHook(key string, value interface{}) *FieldHook {
+	return &FieldHook{Key: key, Value: value}
+}
+
+// NewHostnameHook create a hook which adds the field "hostname" (or 'key' if specified) to the event
+func NewHostnameHook(key ...string) *FieldHook {
+	hostname, _ := os.Hostname()
+	return NewFieldHook(hookKey(key, "hostname"), hostname)
+}
+
+// NewPidHook create a hook which adds the field "pid" (or 'key' if specified) to the event
+func NewPidHook(key ...string) *FieldHook {
+	return NewFieldHook(hookKey(key, "pid"), os.Getpid())
+}
+
+// NewVersionHook create a hook which adds the field "version" (or 'key' if specified) with the build version to the event
+func NewVersionHook(version string, key ...string) *FieldHook {
+	return NewFieldHook(hookKey(key, "version"), version)
+}
+
+// GoroutineHook a hook adds the id of the goroutine which logs the event to the event
+type GoroutineHook struct {
+	Key string
+}
+
+// Fire add the goroutine id field to the event
+func (gh *GoroutineHook) Fire(le *Event) bool {
+	le.AddFields(gh.Key, goroutineID())
+	return true
+}
+
+// NewGoroutineHook create a hook which adds the field "goroutine" (or 'key' if specified) to the event
+func NewGoroutineHook(key ...string) *GoroutineHook {
+	return &GoroutineHook{Key: hookKey(key, "goroutine")}
+}
+
+func hookKey(key []string, def string) string {
+	if len(key) > 0 && key[0] != "" {
+		return key[0]
+	}
+	return def
+}
+
+// goroutineID get the current goroutine id from the stack header "goroutine N [running]:"
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i > 0 {
+		buf = buf[:i]
+	}
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
+
+// MultiHook a multiple hook
+type MultiHook struct {
+	Hooks []Hook
+}
+
+// Fire call the hooks in order, stop and return false if one of the hooks drops the event
+func (mh *MultiHook) Fire(le *Event) bool {
+	for _, h := range mh.Hooks {
+		if !h.Fire(le) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewMultiHook create a multiple hook
+func NewMultiHook(hs ...Hook) *MultiHook {
+	return &MultiHook{Hooks: hs}
+}
+
+// GetHooks get the hooks
+func (log *Log) GetHooks() []Hook {
+	log.hkmutex.RLock()
+	defer log.hkmutex.RUnlock()
+	return log.hooks
+}
+
+// SetHooks set the hooks (replace the current hooks)
+func (log *Log) SetHooks(hs ...Hook) {
+	log.hkmutex.Lock()
+	log.hooks = hs
+	log.hkmutex.Unlock()
+}
+
+// AddHook add the hook 'h' to the end of the hooks
+func (log *Log) AddHook(h Hook) {
+	log.hkmutex.Lock()
+	// copy on write
+	hs := make([]Hook, len(log.hooks), len(log.hooks)+1)
+	copy(hs, log.hooks)
+	log.hooks = append(hs, h)
+	log.hkmutex.Unlock()
+}
+
+// fire call the hooks, return false if the event is dropped
+func (log *Log) fire(le *Event) bool {
+	for _, h := range log.GetHooks() {
+		if !h.Fire(le) {
+			return false
+		}
+	}
+	return true
+}
+
+// HookCreator hook create function
+type HookCreator func(s string) (Hook, error)
+
+var hookCreators = make(map[string]HookCreator)
+
+// RegisterHook register log hook type
+func RegisterHook(name string, hc HookCreator) {
+	hookCreators[name] = hc
+}
+
+// CreateHook create a log hook by name and config
+func CreateHook(name string, conf string) (Hook, error) {
+	if hc, ok := hookCreators[name]; ok {
+		return hc(conf)
+	}
+	return nil, fmt.Errorf("Invalid hook name %q", name)
+}
+
+// NewLogHooks create the log hooks by the configuration string 'c'
+// the hooks are separated by space, example: "hostname pid:process_id version:1.0.0"
+// hostname[:key] - add the host name field (default key: "hostname")
+// pid[:key] - add the process id field (default key: "pid")
+// goroutine[:key] - add the goroutine id field (default key: "goroutine")
+// version:xxx - add the field "version" with the build version xxx
+// redact[:rule,...] - mask the sensitive data by the redact rules (default: email,creditcard,secret)
+func NewLogHooks(c string) ([]Hook, error) {
+	var hs []Hook
+	for _, s := range strings.Fields(c) {
+		name, conf := s, ""
+		if i := strings.IndexByte(s, ':'); i >= 0 {
+			name, conf = s[:i], s[i+1:]
+		}
+
+		h, err := CreateHook(name, conf)
+		if err != nil {
+			return nil, err
+		}
+		hs = append(hs, h)
+	}
+	return hs, nil
+}
+
+func init() {
+	RegisterHook("hostname", func(s string) (Hook, error) {
+		return NewHostnameHook(s), nil
+	})
+	RegisterHook("pid", func(s string) (Hook, error) {
+		return NewPidHook(s), nil
+	})
+	RegisterHook("goroutine", func(s string) (Hook, error) {
+		return NewGoroutineHook(s), nil
+	})
+	RegisterHook("version", func(s string) (Hook, error) {
+		if s == "" {
+			return nil, fmt.Errorf("Missing version of the version hook")
+		}
+		return NewVersionHook(s), nil
+	})
+}
@@ -0,0 +1,182 @@
+package log
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHookEnrich(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		mw := NewMemoryWriter(100)
+		log := NewLog()
+		log.SetWriter(mw)
+		if async {
+			log.Async(100)
+		}
+
+		log.AddHook(NewPidHook())
+		log.AddHook(NewVersionHook("1.2.3", "ver"))
+		log.AddHook(NewGoroutineHook())
+
+		lg := log.GetLogger("app").With("k", "v")
+		lg.Info("info")
+		log.Close()
+
+		les := mw.Snapshot()
+		if assert.Equal(t, 1, len(les), async) {
+			fs := les[0].Fields
+			assert.Equal(t, os.Getpid(), fs["pid"], async)
+			assert.Equal(t, "1.2.3", fs["ver"], async)
+			assert.Equal(t, goroutineID(), fs["goroutine"], async)
+			assert.Equal(t, "v", fs["k"], async)
+		}
+
+		// the logger fields are not modified
+		assert.Equal(t, map[string]interface{}{"k": "v"}, lg.GetFields(), async)
+	}
+}
+
+func TestHookDropAndModify(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		bw := &testBatchWriter{}
+		log := NewLog()
+		log.SetWriter(bw)
+		if async {
+			log.Async(100)
+		}
+
+		log.SetHooks(
+			HookFunc(func(le *Event) bool {
+				return !strings.Contains(le.Msg, "drop")
+			}),
+			HookFunc(func(le *Event) bool {
+				le.Msg = strings.ReplaceAll(le.Msg, "secret", "******")
+				return true
+			}),
+		)
+
+		log.Info("a")
+		log.Info("drop me")
+		log.Info("my secret")
+		log.Close()
+
+		assert.Equal(t, []string{"a", "my ******"}, bw.msgs, async)
+	}
+}
+
+func TestHookGoroutine(t *testing.T) {
+	mw := NewMemoryWriter(100)
+	log := NewLog()
+	log.SetWriter(mw)
+	log.Async(100)
+	log.AddHook(NewGoroutineHook("gid"))
+
+	ids := make(chan uint64, 3)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids <- goroutineID()
+			log.Info("go")
+		}()
+	}
+	wg.Wait()
+	log.Close()
+	close(ids)
+
+	want := map[uint64]bool{}
+	for id := range ids {
+		assert.NotEqual(t, uint64(0), id)
+		want[id] = true
+	}
+
+	got := map[uint64]bool{}
+	for _, le := range mw.Snapshot() {
+		got[le.Fields["gid"].(uint64)] = true
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestHookNotLocked(t *testing.T) {
+	log := NewLog()
+	log.AddHook(NewFieldHook("k", "v"))
+
+	// the hooks are not guarded by the log mutex (held by Flush/SetWriter/Close)
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	done := make(chan bool, 1)
+	go func() {
+		done <- log.fire(&Event{})
+	}()
+
+	select {
+	case ok := <-done:
+		assert.True(t, ok)
+	case <-time.After(time.Second):
+		t.Error("fire is blocked by the log mutex")
+	}
+}
+
+func TestNewLogHooks(t *testing.T) {
+	hs, err := NewLogHooks("hostname pid:process goroutine version:1.0")
+	if assert.Nil(t, err) && assert.Equal(t, 4, len(hs)) {
+		hostname, _ := os.Hostname()
+		assert.Equal(t, &FieldHook{Key: "hostname", Value: hostname}, hs[0])
+		assert.Equal(t, &FieldHook{Key: "process", Value: os.Getpid()}, hs[1])
+		assert.Equal(t, &GoroutineHook{Key: "goroutine"}, hs[2])
+		assert.Equal(t, &FieldHook{Key: "version", Value: "1.0"}, hs[3])
+	}
+
+	hs, err = NewLogHooks("")
+	assert.Nil(t, err)
+	assert.Nil(t, hs)
+
+	for _, s := range []string{"unknown", "pid version"} {
+		_, err = NewLogHooks(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestHookConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "loghook")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	RegisterHook("test", func(s string) (Hook, error) {
+		return NewFieldHook("test", s), nil
+	})
+
+	log := NewLog()
+
+	file := filepath.Join(dir, "log.ini")
+	ioutil.WriteFile(file, []byte("writer = memory\nhook = pid test:x\n"), 0666)
+	if assert.Nil(t, log.Config(file)) {
+		assert.Equal(t, 2, len(log.GetHooks()))
+	}
+
+	file = filepath.Join(dir, "log.json")
+	ioutil.WriteFile(file, []byte(`{"writer": [{"_": "memory"}], "hook": "test:y"}`), 0666)
+	if assert.Nil(t, log.Config(file)) {
+		assert.Equal(t, []Hook{&FieldHook{Key: "test", Value: "y"}}, log.GetHooks())
+	}
+
+	ioutil.WriteFile(file, []byte(`{"writer": [{"_": "memory"}], "hook": "unknown"}`), 0666)
+	assert.NotNil(t, log.Config(file))
+	assert.Equal(t, 1, len(log.GetHooks()))
+
+	ioutil.WriteFile(file, []byte(`{"writer": [{"_": "memory"}], "hook": ""}`), 0666)
+	if assert.Nil(t, log.Config(file)) {
+		assert.Equal(t, 0, len(log.GetHooks()))
+	}
+}
@@ -1,6 +1,9 @@
 package log
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -21,3 +24,95 @@ func TestParseLevel(t *testing.T) {
 	assert.Equal(t, LevelTrace, ParseLevel("T"))
 	assert.Equal(t, LevelTrace, ParseLevel("t"))
 }
+
+func TestLoggerLevels(t *testing.T) {
+	log := NewLog()
+	log.SetLevel(LevelInfo)
+	log.SetLevels(map[string]Level{
+		"com.app":        LevelDebug,
+		"com.app.db":     LevelWarn,
+		"com.app.db.*":   LevelError,
+		"*.http":         LevelTrace,
+		"com.app.*.http": LevelFatal,
+		"db.*":           LevelError,
+		"db.?":           LevelWarn,
+	})
+
+	cs := []struct {
+		name string
+		want Level
+	}{
+		{"", LevelInfo},
+		{"com", LevelInfo},
+		{"com.app", LevelDebug},
+		{"com.app.web", LevelDebug},
+		{"com.app.db", LevelWarn},
+		{"com.app.db.sql", LevelError},
+		{"web.http", LevelTrace},
+		{"com.app.web.http", LevelFatal},
+		{"com.app.db.http", LevelFatal},
+		{"db.x", LevelWarn},
+		{"db.xy", LevelError},
+		{"dbx", LevelInfo},
+	}
+
+	for i := 0; i < 2; i++ {
+		// the second round is resolved by the cache
+		for _, c := range cs {
+			assert.Equal(t, c.want, log.GetLogger(c.name).GetLevel(), c.name)
+		}
+	}
+
+	// global level changes the unmatched loggers
+	log.SetLevel(LevelWarn)
+	assert.Equal(t, LevelWarn, log.GetLogger("com").GetLevel())
+	assert.Equal(t, LevelDebug, log.GetLogger("com.app.web").GetLevel())
+
+	// the cache is cleared by SetLevels
+	log.SetLevels(map[string]Level{"com": LevelTrace})
+	assert.Equal(t, LevelTrace, log.GetLogger("com.app.web").GetLevel())
+	assert.Equal(t, LevelWarn, log.GetLogger("web.http").GetLevel())
+}
+
+func TestLoggerLevelsConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "loglevel")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "log.ini")
+	ioutil.WriteFile(file, []byte("writer = stdout\n[level]\n* = warn\ncom.app = debug\n*.http = trace\n"), 0666)
+
+	log := NewLog()
+	if !assert.Nil(t, log.Config(file)) {
+		return
+	}
+
+	assert.Equal(t, LevelWarn, log.GetLogger("web").GetLevel())
+	assert.Equal(t, LevelDebug, log.GetLogger("com.app.db").GetLevel())
+	assert.Equal(t, LevelDebug, log.GetLogger("com.app.http").GetLevel())
+	assert.Equal(t, LevelTrace, log.GetLogger("web.http").GetLevel())
+}
+
+func TestSetLoggerLevel(t *testing.T) {
+	log := NewLog()
+	log.SetLevel(LevelInfo)
+	log.GetLogger("web")
+	log.GetLogger("sql")
+	log.GetLogger("web")
+
+	assert.Equal(t, []string{"sql", "web"}, log.GetLoggerNames())
+
+	log.SetLoggerLevel("web", LevelDebug)
+	assert.Equal(t, LevelDebug, log.GetLogger("web").GetLevel())
+	assert.Equal(t, map[string]Level{"web": LevelDebug}, log.GetLevels())
+
+	lvls := log.GetLevels()
+	lvls["sql"] = LevelTrace
+	assert.Equal(t, LevelInfo, log.GetLogger("sql").GetLevel())
+
+	log.SetLoggerLevel("web", LevelNone)
+	assert.Equal(t, LevelInfo, log.GetLogger("web").GetLevel())
+	assert.Equal(t, 0, len(log.GetLevels()))
+}
@@ -1,26 +1,49 @@
 package log
 
 import (
+	"context"
 	"io"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+
+	"github.com/pandafw/pango/str/wildcard"
 )
 
 // Log is default logger in application.
 // it can contain several writers and log message into all writers.
 type Log struct {
+	dropped uint64                 // dropped event count (64-bit aligned for atomic)
+	counts  [LevelTrace + 1]uint64 // event counts by level (64-bit aligned for atomic)
+
 	logger *logger
 	level  Level
 	trace  Level
 
-	async   bool
-	evtChan chan *Event
-	sigChan chan string
-	waitg   sync.WaitGroup
-	writer  Writer
-	mutex   sync.Mutex
-	levels  map[string]Level
-	logfmt  Formatter
-}
+	async    bool
+	overflow Overflow
+	ovlevel  Level
+	evtChan  chan *Event
+	sigChan  chan string
+	waitg    sync.WaitGroup
+	writer   Writer
+	pending  func() // the pending switch function executed by the async goroutine
+	mutex    sync.Mutex
+	fatalh   FatalHandler
+	hooks    []Hook // copy on write
+	hkmutex  sync.RWMutex
+	levels   map[string]Level
+	lvlcache map[string]Level    // the resolved logger levels
+	names    map[string]struct{} // the logger names created by GetLogger()
+	lvlmutex sync.RWMutex
+	lgcounts map[string]*loggerCounts // event counts by logger name
+	metmutex sync.RWMutex
+	logfmt   Formatter
+}
+
+// max count of the resolved logger levels cache
+const maxLevelCache = 10000
 
 // NewLog returns a new Log.
 func NewLog() *Log {
@@ -40,22 +63,169 @@ func newLog(depth int) *Log {
 	return log
 }
 
-// SetLevels set the logger levels
+// SetLevels set the logger levels.
+// The key can be a logger name ("com.app.db"), a parent logger name ("com.app" for "com.app.db")
+// or a wildcard pattern ("db.*", "*.http").
+// The most specific key wins, see resolveLoggerLevel().
 func (log *Log) SetLevels(lvls map[string]Level) {
+	log.lvlmutex.Lock()
+	log.levels = lvls
+	log.lvlcache = nil
+	log.lvlmutex.Unlock()
+}
+
+// GetLevels get a copy of the logger levels
+func (log *Log) GetLevels() map[string]Level {
+	log.lvlmutex.RLock()
+	defer log.lvlmutex.RUnlock()
+
+	lvls := make(map[string]Level, len(log.levels))
+	for k, v := range log.levels {
+		lvls[k] = v
+	}
+	return lvls
+}
+
+// SetLoggerLevel set the level of the logger name (or the wildcard pattern) 'name'.
+// LevelNone: remove the logger level.
+func (log *Log) SetLoggerLevel(name string, lvl Level) {
+	log.lvlmutex.Lock()
+	defer log.lvlmutex.Unlock()
+
+	// copy on write
+	lvls := make(map[string]Level, len(log.levels)+1)
+	for k, v := range log.levels {
+		lvls[k] = v
+	}
+	if lvl == LevelNone {
+		delete(lvls, name)
+	} else {
+		lvls[name] = lvl
+	}
+
 	log.levels = lvls
+	log.lvlcache = nil
+}
+
+// GetLoggerNames get the sorted names of the loggers created by GetLogger()
+func (log *Log) GetLoggerNames() []string {
+	log.lvlmutex.RLock()
+	names := make([]string, 0, len(log.names))
+	for n := range log.names {
+		names = append(names, n)
+	}
+	log.lvlmutex.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// addLoggerName record the logger name created by GetLogger()
+func (log *Log) addLoggerName(name string) {
+	log.lvlmutex.RLock()
+	_, ok := log.names[name]
+	log.lvlmutex.RUnlock()
+
+	if !ok {
+		log.lvlmutex.Lock()
+		if log.names == nil {
+			log.names = make(map[string]struct{})
+		}
+		if len(log.names) < maxLevelCache {
+			log.names[name] = struct{}{}
+		}
+		log.lvlmutex.Unlock()
+	}
 }
 
 // getLoggerLevel get the named logger level
 func (log *Log) getLoggerLevel(name string) Level {
-	level := log.levels[name]
+	log.lvlmutex.RLock()
+	level, ok := log.lvlcache[name]
+	log.lvlmutex.RUnlock()
+
+	if !ok {
+		log.lvlmutex.Lock()
+		level = resolveLoggerLevel(log.levels, name)
+		if log.lvlcache == nil || len(log.lvlcache) >= maxLevelCache {
+			log.lvlcache = make(map[string]Level)
+		}
+		log.lvlcache[name] = level
+		log.lvlmutex.Unlock()
+	}
+
 	if level == LevelNone {
 		level = log.GetLevel()
 	}
 	return level
 }
 
+// levelKey a candidate key of the logger level
+type levelKey struct {
+	key   string
+	lits  int  // count of the literal characters
+	stars int  // count of the '*' wildcards
+	wild  bool // the key is a wildcard pattern
+}
+
+// moreSpecific check the key 'a' is more specific than the key 'b'
+func (a *levelKey) moreSpecific(b *levelKey) bool {
+	if a.lits != b.lits {
+		return a.lits > b.lits
+	}
+	if a.wild != b.wild {
+		return a.wild
+	}
+	if a.stars != b.stars {
+		return a.stars < b.stars
+	}
+	return a.key < b.key
+}
+
+// resolveLoggerLevel find the level of the most specific key in 'lvls' for the logger 'name'.
+// The candidate keys are:
+//   - the logger name itself (always wins)
+//   - the parent logger names ("com.app" and "com" for "com.app.db"), treated as the pattern "com.app.*"
+//   - the wildcard patterns ('*' and '?') which match the logger name
+//
+// The key with the most literal (non-wildcard) characters wins,
+// then the wildcard pattern wins the parent logger name,
+// then the key with less '*' wildcards, then the key which is lexically smaller.
+func resolveLoggerLevel(lvls map[string]Level, name string) Level {
+	if lvl, ok := lvls[name]; ok {
+		return lvl
+	}
+
+	var (
+		level Level
+		best  *levelKey
+	)
+
+	for k, lvl := range lvls {
+		var lk *levelKey
+
+		if strings.ContainsAny(k, "*?") {
+			if !wildcard.Match(k, name) {
+				continue
+			}
+			stars := strings.Count(k, "*")
+			lk = &levelKey{key: k, lits: len(k) - stars - strings.Count(k, "?"), stars: stars, wild: true}
+		} else if strings.HasPrefix(name, k+".") {
+			lk = &levelKey{key: k, lits: len(k) + 1, stars: 1}
+		} else {
+			continue
+		}
+
+		if best == nil || lk.moreSpecific(best) {
+			level, best = lvl, lk
+		}
+	}
+	return level
+}
+
 // GetLogger returns a new Logger with name
 func (log *Log) GetLogger(name string) Logger {
+	log.addLoggerName(name)
 	return &logger{
 		log:   log,
 		name:  name,
@@ -69,16 +239,22 @@ func (log *Log) Async(size int) *Log {
 	log.mutex.Lock()
 	defer log.mutex.Unlock()
 
+	log.setAsync(size)
+	return log
+}
+
+// setAsync start, resize or stop (size < 1) the async goroutine, must be called in the mutex lock
+func (log *Log) setAsync(size int) {
 	if size < 1 {
 		if log.async {
 			log.stopAsync()
 		}
-		return log
+		return
 	}
 
 	if log.async {
-		if size == len(log.evtChan) {
-			return log
+		if size == cap(log.evtChan) {
+			return
 		}
 		log.stopAsync()
 	}
@@ -87,21 +263,50 @@ func (log *Log) Async(size int) *Log {
 	log.evtChan = make(chan *Event, size)
 	log.sigChan = make(chan string, 1)
 	go log.startAsync()
-	return log
 }
 
 // GetWriter get the log writer
 func (log *Log) GetWriter() Writer {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
 	return log.writer
 }
 
-// SetWriter set the log writer
+// SetWriter set the log writer.
+// In async mode, the queued events are written to the old writer before the writer is switched.
 func (log *Log) SetWriter(lw Writer) {
 	log.mutex.Lock()
 	defer log.mutex.Unlock()
 
-	log.close()
-	log.writer = lw
+	log.exchange(func() {
+		log.close()
+		log.writer = lw
+	})
+}
+
+// SwapWriter replace the log writer by 'lw' and return the previous writer.
+// The previous writer is flushed but not closed.
+func (log *Log) SwapWriter(lw Writer) Writer {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	ow := log.writer
+	log.exchange(func() {
+		log.flush()
+		log.writer = lw
+	})
+	return ow
+}
+
+// exchange execute the switch function 'sw' in the async goroutine after the queued events are written,
+// or execute it directly in sync mode. It must be called in the mutex lock.
+func (log *Log) exchange(sw func()) {
+	if log.async {
+		log.pending = sw
+		log.execSignal("switch")
+		return
+	}
+	sw()
 }
 
 // Flush flush all chan data.
@@ -162,9 +367,12 @@ func (log *Log) startAsync() {
 		case le := <-log.evtChan:
 			log.write(le)
 		case sg := <-log.sigChan:
-			// Now should only send "flush" or "close" to bl.sigChan
+			// Now should only send "flush", "switch", "close" or "done" to bl.sigChan
 			log.flush()
 			switch sg {
+			case "switch":
+				log.pending()
+				log.pending = nil
 			case "close":
 				log.close()
 				done = true
@@ -206,10 +414,36 @@ func (log *Log) write(le *Event) {
 	putEvent(le)
 }
 
-// submit submit a log event
+// submit submit a log event.
+// The hooks are called first, the event is dropped if one of the hooks returns false.
+// For a FATAL event, the log is flushed and the fatal handler is called.
 func (log *Log) submit(le *Event) {
+	if !log.fire(le) {
+		putEvent(le)
+		return
+	}
+
+	log.count(le)
+
+	if le.Level == LevelFatal {
+		if fh := log.GetFatalHandler(); fh != nil {
+			// copy the event, because the event will be put back to the pool
+			ce := &Event{}
+			*ce = *le
+
+			log.enqueueOrWrite(le)
+			log.fatal(fh, ce)
+			return
+		}
+	}
+
+	log.enqueueOrWrite(le)
+}
+
+// enqueueOrWrite enqueue the event in async mode, or write the event
+func (log *Log) enqueueOrWrite(le *Event) {
 	if log.async {
-		log.evtChan <- le
+		log.enqueue(le)
 		return
 	}
 
@@ -264,12 +498,12 @@ func (log *Log) SetCallerDepth(d int) {
 
 // GetLevel return the logger's level
 func (log *Log) GetLevel() Level {
-	return log.level
+	return Level(atomic.LoadUint32((*uint32)(&log.level)))
 }
 
-// SetLevel set the logger's level
+// SetLevel set the logger's level (it can be changed at runtime safely)
 func (log *Log) SetLevel(lvl Level) {
-	log.level = lvl
+	atomic.StoreUint32((*uint32)(&log.level), uint32(lvl))
 }
 
 // GetTraceLevel return the logger's trace level
@@ -316,6 +550,21 @@ func (log *Log) SetProps(props map[string]interface{}) {
 	log.logger.SetProps(props)
 }
 
+// GetFields get logger structured fields
+func (log *Log) GetFields() map[string]interface{} {
+	return log.logger.fields
+}
+
+// With returns a child logger with the key/value pairs 'kvs' added to the structured fields
+func (log *Log) With(kvs ...interface{}) Logger {
+	return &logger{
+		log:    log,
+		name:   log.logger.name,
+		depth:  log.logger.depth,
+		fields: addFields(log.logger.fields, kvs),
+	}
+}
+
 // GetFormatter get logger formatter
 func (log *Log) GetFormatter() Formatter {
 	return log.logfmt
@@ -328,7 +577,7 @@ func (log *Log) SetFormatter(lf Formatter) {
 
 // IsLevelEnabled is specified level enabled
 func (log *Log) IsLevelEnabled(lvl Level) bool {
-	return log.level > lvl
+	return log.GetLevel() > lvl
 }
 
 // Log log a message at specified level.
@@ -430,3 +679,38 @@ func (log *Log) Trace(v ...interface{}) {
 func (log *Log) Tracef(f string, v ...interface{}) {
 	log.logger._logf(LevelTrace, f, v...)
 }
+
+// LogCtx log a message at specified level with the request-scoped fields of ctx.
+func (log *Log) LogCtx(ctx context.Context, lvl Level, v ...interface{}) {
+	log.logger._logCtx(ctx, lvl, v...)
+}
+
+// FatalCtx log a message at fatal level with the request-scoped fields of ctx.
+func (log *Log) FatalCtx(ctx context.Context, v ...interface{}) {
+	log.logger._logCtx(ctx, LevelFatal, v...)
+}
+
+// ErrorCtx log a message at error level with the request-scoped fields of ctx.
+func (log *Log) ErrorCtx(ctx context.Context, v ...interface{}) {
+	log.logger._logCtx(ctx, LevelError, v...)
+}
+
+// WarnCtx log a message at warning level with the request-scoped fields of ctx.
+func (log *Log) WarnCtx(ctx context.Context, v ...interface{}) {
+	log.logger._logCtx(ctx, LevelWarn, v...)
+}
+
+// InfoCtx log a message at info level with the request-scoped fields of ctx.
+func (log *Log) InfoCtx(ctx context.Context, v ...interface{}) {
+	log.logger._logCtx(ctx, LevelInfo, v...)
+}
+
+// DebugCtx log a message at debug level with the request-scoped fields of ctx.
+func (log *Log) DebugCtx(ctx context.Context, v ...interface{}) {
+	log.logger._logCtx(ctx, LevelDebug, v...)
+}
+
+// TraceCtx log a message at trace level with the request-scoped fields of ctx.
+func (log *Log) TraceCtx(ctx context.Context, v ...interface{}) {
+	log.logger._logCtx(ctx, LevelTrace, v...)
+}
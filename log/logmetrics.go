@@ -0,0 +1,232 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics the metrics of the log
+type Metrics struct {
+	Events        map[Level]uint64            // the count of the events by level
+	LoggerEvents  map[string]map[Level]uint64 // the count of the events by logger name and level
+	Dropped       uint64                      // the count of the events dropped by the async overflow policy
+	QueueLength   int                         // the count of the queued events in async mode
+	QueueCapacity int                         // the capacity of the event queue in async mode
+	Writers       []*WriterStats              // the statistics of the writers (depth first order of the writer tree)
+}
+
+// WriterStats the statistics of a writer
+type WriterStats struct {
+	Name          string    // the writer name, example: FileWriter("app.log")
+	Rejected      uint64    // the count of the events rejected by the writer filter
+	Errors        uint64    // the count of the writer errors
+	LastError     string    // the last error message
+	LastErrorTime time.Time // the time of the last error
+}
+
+// loggerCounts the event counts of a logger by level
+type loggerCounts [LevelTrace + 1]uint64
+
+// count count the event by level and logger name
+func (log *Log) count(le *Event) {
+	if le.Level > LevelTrace {
+		return
+	}
+
+	atomic.AddUint64(&log.counts[le.Level], 1)
+
+	name := ""
+	if le.Logger != nil {
+		name = le.Logger.GetName()
+	}
+
+	log.metmutex.RLock()
+	lc, ok := log.lgcounts[name]
+	log.metmutex.RUnlock()
+
+	if !ok {
+		log.metmutex.Lock()
+		if lc, ok = log.lgcounts[name]; !ok {
+			if log.lgcounts == nil {
+				log.lgcounts = make(map[string]*loggerCounts)
+			}
+			if len(log.lgcounts) >= maxLevelCache {
+				log.metmutex.Unlock()
+				return
+			}
+			lc = &loggerCounts{}
+			log.lgcounts[name] = lc
+		}
+		log.metmutex.Unlock()
+	}
+
+	atomic.AddUint64(&lc[le.Level], 1)
+}
+
+// GetMetrics get the metrics of the log.
+// The writer statistics are gathered from the writer of the log and the writers wrapped by it.
+func (log *Log) GetMetrics() *Metrics {
+	m := &Metrics{
+		Events:       make(map[Level]uint64),
+		LoggerEvents: make(map[string]map[Level]uint64),
+		Dropped:      log.GetDroppedCount(),
+	}
+
+	for lvl := LevelFatal; lvl <= LevelTrace; lvl++ {
+		m.Events[lvl] = atomic.LoadUint64(&log.counts[lvl])
+	}
+
+	log.metmutex.RLock()
+	for name, lc := range log.lgcounts {
+		lm := make(map[Level]uint64)
+		for lvl := LevelFatal; lvl <= LevelTrace; lvl++ {
+			if n := atomic.LoadUint64(&lc[lvl]); n > 0 {
+				lm[lvl] = n
+			}
+		}
+		m.LoggerEvents[name] = lm
+	}
+	log.metmutex.RUnlock()
+
+	log.mutex.Lock()
+	if log.async {
+		m.QueueLength = len(log.evtChan)
+		m.QueueCapacity = cap(log.evtChan)
+	}
+	lw := log.writer
+	log.mutex.Unlock()
+
+	m.Writers = writerStats(lw)
+
+	return m
+}
+
+// writerStats get the statistics of the writer 'w' and the writers wrapped by it
+// (MultiWriter, RouteWriter and the writers with the method Unwrap()) in the depth first order.
+func writerStats(w Writer) []*WriterStats {
+	var wss []*WriterStats
+	wm := make(map[Writer]bool)
+
+	var walk func(w Writer)
+	walk = func(w Writer) {
+		if w == nil {
+			return
+		}
+		if isComparable(w) {
+			if wm[w] {
+				return
+			}
+			wm[w] = true
+		}
+
+		if sw, ok := w.(statWriter); ok {
+			wss = append(wss, sw.stats().snapshot(writerName(w)))
+		}
+
+		switch xw := w.(type) {
+		case *MultiWriter:
+			for _, w := range xw.Writers {
+				walk(w)
+			}
+		case *RouteWriter:
+			for _, r := range xw.Routes {
+				walk(r.Writer)
+			}
+		case writerWrapper:
+			walk(xw.Unwrap())
+		}
+	}
+
+	walk(w)
+	return wss
+}
+
+// writerName get the name of the writer, the name is same as the prefix of the error message
+func writerName(w Writer) string {
+	switch lw := w.(type) {
+	case *FileWriter:
+		return fmt.Sprintf("FileWriter(%q)", lw.Path)
+	case *ConnWriter:
+		return fmt.Sprintf("ConnWriter(%q)", lw.Addr)
+	case *SyslogWriter:
+		return fmt.Sprintf("SyslogWriter(%q)", lw.Addr)
+	case *SMTPWriter:
+		return fmt.Sprintf("SMTPWriter(%s:%d)", lw.Host, lw.Port)
+	}
+
+	// do not use the webhook url as name, it may contain the secret token
+	return strings.TrimPrefix(fmt.Sprintf("%T", w), "*log.")
+}
+
+// statWriter a writer which keeps the statistics
+type statWriter interface {
+	stats() *writerStat
+}
+
+// writerStat the statistics of a writer
+type writerStat struct {
+	once  sync.Once
+	mutex sync.Mutex   // the mutex of the errors
+	stats *WriterStats // allocated at the first use (64-bit aligned for the atomic Rejected)
+}
+
+// get get (create if not exists) the statistics
+func (ws *writerStat) get() *WriterStats {
+	ws.once.Do(func() {
+		ws.stats = &WriterStats{}
+	})
+	return ws.stats
+}
+
+// snapshot return a copy of the statistics with the writer name 'name'
+func (ws *writerStat) snapshot(name string) *WriterStats {
+	st := ws.get()
+
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	return &WriterStats{
+		Name:          name,
+		Rejected:      atomic.LoadUint64(&st.Rejected),
+		Errors:        st.Errors,
+		LastError:     st.LastError,
+		LastErrorTime: st.LastErrorTime,
+	}
+}
+
+// isComparable check the writer can be used as a map key
+func isComparable(w Writer) bool {
+	return reflect.TypeOf(w).Comparable()
+}
+
+// reject check the event 'le' is rejected by the filter 'f' of the writer, and count the rejected event to 'ws'
+func reject(ws *writerStat, f Filter, le *Event) bool {
+	if f == nil || !f.Reject(le) {
+		return false
+	}
+
+	atomic.AddUint64(&ws.get().Rejected, 1)
+	return true
+}
+
+// writerErrorf print the error message of the writer 'w' to os.Stderr, and record it as the last error of the writer
+func writerErrorf(w Writer, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	fmt.Fprint(os.Stderr, msg)
+
+	if sw, ok := w.(statWriter); ok {
+		ws := sw.stats()
+		st := ws.get()
+
+		ws.mutex.Lock()
+		st.Errors++
+		st.LastError = strings.TrimSpace(msg)
+		st.LastErrorTime = time.Now()
+		ws.mutex.Unlock()
+	}
+}
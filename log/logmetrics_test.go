@@ -0,0 +1,147 @@
+package log
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func findWriterStats(log *Log, name string) *WriterStats {
+	for _, ws := range log.GetMetrics().Writers {
+		if ws.Name == name {
+			return ws
+		}
+	}
+	return nil
+}
+
+func TestMetricsEvents(t *testing.T) {
+	log := NewLog()
+	log.SetWriter(NewMemoryWriter(10))
+	log.SetLevel(LevelDebug)
+
+	log.Info("a")
+	log.Info("b")
+	log.Trace("filtered")
+	log.GetLogger("sql").Debug("c")
+	log.GetLogger("sql").Error("d")
+	log.GetLogger("web").Warn("e")
+
+	m := log.GetMetrics()
+	assert.Equal(t, map[Level]uint64{
+		LevelFatal: 0,
+		LevelError: 1,
+		LevelWarn:  1,
+		LevelInfo:  2,
+		LevelDebug: 1,
+		LevelTrace: 0,
+	}, m.Events)
+	assert.Equal(t, map[string]map[Level]uint64{
+		"":    {LevelInfo: 2},
+		"sql": {LevelDebug: 1, LevelError: 1},
+		"web": {LevelWarn: 1},
+	}, m.LoggerEvents)
+	assert.Equal(t, 0, m.QueueCapacity)
+}
+
+func TestMetricsQueue(t *testing.T) {
+	bw := &testBatchWriter{}
+	log := NewLog()
+	log.SetWriter(bw)
+	log.Async(10)
+	defer log.Close()
+
+	m := log.GetMetrics()
+	assert.Equal(t, 0, m.QueueLength)
+	assert.Equal(t, 10, m.QueueCapacity)
+}
+
+func TestMetricsWriterRejected(t *testing.T) {
+	mw := NewMemoryWriter(10)
+	mw.SetFilter("level:error")
+
+	log := NewLog()
+	log.SetWriter(mw)
+	log.Error("a")
+	log.Info("b")
+	log.Debug("c")
+
+	if ws := findWriterStats(log, "MemoryWriter"); assert.NotNil(t, ws) {
+		assert.Equal(t, uint64(2), ws.Rejected)
+	}
+
+	// the statistics are kept by the writer, not shared by the other logs
+	log2 := NewLog()
+	log2.SetWriter(NewMemoryWriter(10))
+	if ws := findWriterStats(log2, "MemoryWriter"); assert.NotNil(t, ws) {
+		assert.Equal(t, uint64(0), ws.Rejected)
+	}
+}
+
+func TestMetricsWriterRejectedConcurrent(t *testing.T) {
+	mw := NewMemoryWriter(10)
+	mw.SetFilter("level:error")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				mw.Write(&Event{Level: LevelInfo})
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, uint64(1000), mw.stats().snapshot("").Rejected)
+}
+
+func TestMetricsWriterErrors(t *testing.T) {
+	fw := &FileWriter{Path: "metrics-test.log"}
+	name := `FileWriter("metrics-test.log")`
+
+	writerErrorf(fw, "FileWriter(%q) - Write(): %v\n", fw.Path, errors.New("err1"))
+	writerErrorf(fw, "FileWriter(%q) - Sync(): %v\n", fw.Path, errors.New("err2"))
+
+	wss := writerStats(fw)
+	if assert.Equal(t, 1, len(wss)) {
+		ws := wss[0]
+		assert.Equal(t, name, ws.Name)
+		assert.Equal(t, uint64(2), ws.Errors)
+		assert.Equal(t, `FileWriter("metrics-test.log") - Sync(): err2`, ws.LastError)
+		assert.False(t, ws.LastErrorTime.IsZero())
+	}
+
+	// the other writer with the same name
+	assert.Equal(t, uint64(0), writerStats(&FileWriter{Path: "metrics-test.log"})[0].Errors)
+}
+
+func TestMetricsWriterTree(t *testing.T) {
+	sw := &StreamWriter{}
+	fw := &FileWriter{Path: "metrics-test.log"}
+	mw := NewMemoryWriter(10)
+
+	rw := NewRouteWriter()
+	rw.AddRoute("level:error", fw)
+	rw.AddRoute("", fw)
+	rw.AddRoute("", NewRedactWriter(mw, NewRedactor()))
+
+	w := NewMultiWriter(sw, NewAsyncWriter(NewAggregateWriter(rw, 0), 10))
+	defer w.Close()
+
+	var names []string
+	for _, ws := range writerStats(w) {
+		names = append(names, ws.Name)
+	}
+	assert.Equal(t, []string{"StreamWriter", "AggregateWriter", "RouteWriter", `FileWriter("metrics-test.log")`, "MemoryWriter"}, names)
+}
+
+func TestWriterName(t *testing.T) {
+	assert.Equal(t, `FileWriter("a.log")`, writerName(&FileWriter{Path: "a.log"}))
+	assert.Equal(t, `ConnWriter("localhost:9999")`, writerName(&ConnWriter{Addr: "localhost:9999"}))
+	assert.Equal(t, `SMTPWriter(localhost:25)`, writerName(&SMTPWriter{Host: "localhost", Port: 25}))
+	assert.Equal(t, `WebhookWriter`, writerName(&WebhookWriter{Webhook: "http://x/?token=t"}))
+}
@@ -0,0 +1,423 @@
+// Package logread read the log files written by the log.FileWriter
+// (include the rotated and compressed files) in chronological order,
+// parse the JSON formatted log lines to log.Event, and filter them by time range, level and logger name.
+// Usage:
+//
+//	lr := logread.NewReader("logs/app.log")
+//	lr.Filter.Level = log.LevelWarn
+//	lr.Filter.Start = time.Now().Add(-time.Hour)
+//	defer lr.Close()
+//
+//	for {
+//		le, err := lr.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+//
+package logread
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pandafw/pango/log"
+	"github.com/pandafw/pango/str/wildcard"
+)
+
+// Keys the json keys of the log event
+type Keys struct {
+	When   string
+	Level  string
+	Logger string
+	Msg    string
+	File   string
+	Line   string
+	Func   string
+	Trace  string
+}
+
+// DefaultKeys the json keys of the log.JSONFmtDefault format.
+// JSONFmtDefault does not output the logger name, set Keys.Logger if the format has it (e.g. "logger": %c).
+var DefaultKeys = Keys{
+	When:  "when",
+	Level: "level",
+	Msg:   "msg",
+	File:  "file",
+	Line:  "line",
+	Func:  "func",
+	Trace: "trace",
+}
+
+// ECSKeys the json keys of the log.ECSFormatter
+var ECSKeys = Keys{
+	When:   "@timestamp",
+	Level:  "log.level",
+	Logger: "log.logger",
+	Msg:    "message",
+	File:   "log.origin.file.name",
+	Line:   "log.origin.file.line",
+	Func:   "log.origin.function",
+	Trace:  "error.stack_trace",
+}
+
+// Filter the log event filter
+type Filter struct {
+	Start  time.Time // the start time (inclusive), zero: no limit
+	End    time.Time // the end time (exclusive), zero: no limit
+	Level  log.Level // the event level is equal or higher than Level, LevelNone: all levels
+	Logger string    // the logger name or wildcard pattern ('*' and '?' are supported, e.g. "web.*"), "" for all loggers
+}
+
+// Match check the event matches the filter
+func (f *Filter) Match(le *log.Event) bool {
+	if !f.Start.IsZero() && le.When.Before(f.Start) {
+		return false
+	}
+	if !f.End.IsZero() && !le.When.Before(f.End) {
+		return false
+	}
+	if f.Level != log.LevelNone && le.Level > f.Level {
+		return false
+	}
+	if f.Logger != "" && !wildcard.Match(f.Logger, le.Logger.GetName()) {
+		return false
+	}
+	return true
+}
+
+// Reader read the log events from the log file and the rotated files
+type Reader struct {
+	Path       string         // the log file path (same as the log.FileWriter.Path)
+	Keys       Keys           // the json keys of the log event
+	TimeLayout string         // the time layout, default: "2006-01-02T15:04:05.000" (RFC3339 is also accepted)
+	Location   *time.Location // the time location, default: time.Local
+	Filter     Filter         // the log event filter
+
+	log   *log.Log
+	files []string
+	index int
+	file  *os.File
+	rc    io.ReadCloser
+	br    *bufio.Reader
+}
+
+// NewReader create a log reader for the log file 'path'
+func NewReader(path string) *Reader {
+	return &Reader{
+		Path:       path,
+		Keys:       DefaultKeys,
+		TimeLayout: "2006-01-02T15:04:05.000",
+		Location:   time.Local,
+	}
+}
+
+// Files list the log file and the rotated files in chronological order (oldest first).
+func (r *Reader) Files() ([]string, error) {
+	path := r.Path
+	dir := filepath.Dir(path)
+	if filepath.Ext(path) == "" {
+		path += ".log"
+	}
+
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fis, err := f.Readdir(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	var rfs []os.FileInfo
+	for _, fi := range fis {
+		if fi.IsDir() || !log.IsRotatedName(path, fi.Name()) {
+			continue
+		}
+
+		// skip the files which are written before the start time
+		if !r.Filter.Start.IsZero() && fi.ModTime().Before(r.Filter.Start) {
+			continue
+		}
+		rfs = append(rfs, fi)
+	}
+
+	sort.Slice(rfs, func(i, j int) bool {
+		ti, tj := rfs[i].ModTime(), rfs[j].ModTime()
+		if ti.Equal(tj) {
+			return rfs[i].Name() < rfs[j].Name()
+		}
+		return ti.Before(tj)
+	})
+
+	files := make([]string, 0, len(rfs)+1)
+	for _, fi := range rfs {
+		files = append(files, filepath.Join(dir, fi.Name()))
+	}
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// Next read the next matched log event, return io.EOF if no more events.
+// The lines which can not be parsed are skipped.
+func (r *Reader) Next() (*log.Event, error) {
+	if r.files == nil {
+		files, err := r.Files()
+		if err != nil {
+			return nil, err
+		}
+		r.files = files
+		r.index = 0
+	}
+
+	for {
+		if r.br == nil {
+			if r.index >= len(r.files) {
+				return nil, io.EOF
+			}
+			path := r.files[r.index]
+			r.index++
+			err := r.open(path)
+			if os.IsNotExist(err) {
+				// compressed or deleted after listed
+				if cp := compressedPath(path); cp != "" {
+					err = r.open(cp)
+				}
+				if os.IsNotExist(err) {
+					continue
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		line, err := r.br.ReadBytes('\n')
+		if len(line) > 0 {
+			if le, er := r.Parse(line); er == nil && r.Filter.Match(le) {
+				return le, nil
+			}
+		}
+
+		if err != nil {
+			r.closeFile()
+			if err != io.EOF {
+				return nil, err
+			}
+		}
+	}
+}
+
+// ReadAll read all the matched log events
+func (r *Reader) ReadAll() ([]*log.Event, error) {
+	var les []*log.Event
+	for {
+		le, err := r.Next()
+		if err == io.EOF {
+			return les, nil
+		}
+		if err != nil {
+			return les, err
+		}
+		les = append(les, le)
+	}
+}
+
+// Tail read the last n matched log events (all events if n <= 0)
+func (r *Reader) Tail(n int) ([]*log.Event, error) {
+	if n <= 0 {
+		return r.ReadAll()
+	}
+
+	// ring buffer, 'i' is the index of the oldest event when the buffer is full
+	les := make([]*log.Event, 0, n)
+	i := 0
+	for {
+		le, err := r.Next()
+		if err != nil {
+			if i > 0 {
+				les = append(les[i:len(les):len(les)], les[:i]...)
+			}
+			if err == io.EOF {
+				err = nil
+			}
+			return les, err
+		}
+
+		if len(les) < n {
+			les = append(les, le)
+		} else {
+			les[i] = le
+			i = (i + 1) % n
+		}
+	}
+}
+
+// Close close the reader
+func (r *Reader) Close() error {
+	r.files = nil
+	return r.closeFile()
+}
+
+func (r *Reader) open(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	var rd io.Reader = f
+	if c := log.GetCompressorByExt(path); c != nil {
+		rc, err := c.NewReader(f)
+		if err != nil {
+			f.Close()
+			return fmt.Errorf("logread: failed to decompress %q: %v", path, err)
+		}
+		r.rc = rc
+		rd = rc
+	}
+
+	r.file = f
+	r.br = bufio.NewReader(rd)
+	return nil
+}
+
+// compressedPath find the compressed file of the path, return "" if not found
+func compressedPath(path string) string {
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	f, err := os.Open(dir)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	names, err := f.Readdirnames(-1)
+	if err != nil {
+		return ""
+	}
+
+	for _, n := range names {
+		if c := log.GetCompressorByExt(n); c != nil && n[:len(n)-len(c.Ext())] == name {
+			return filepath.Join(dir, n)
+		}
+	}
+	return ""
+}
+
+func (r *Reader) closeFile() (err error) {
+	if r.rc != nil {
+		r.rc.Close()
+		r.rc = nil
+	}
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+	r.br = nil
+	return
+}
+
+// Parse parse the JSON formatted log line to a log event.
+// The values which keys are not defined in Keys are set to the Fields of the event.
+func (r *Reader) Parse(line []byte) (*log.Event, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] != '{' {
+		return nil, fmt.Errorf("logread: invalid log line %q", line)
+	}
+
+	m := make(map[string]interface{})
+	jd := json.NewDecoder(bytes.NewReader(line))
+	jd.UseNumber()
+	if err := jd.Decode(&m); err != nil {
+		return nil, err
+	}
+
+	if r.log == nil {
+		r.log = log.NewLog()
+	}
+
+	le := &log.Event{}
+	name := ""
+	for k, v := range m {
+		switch k {
+		case r.Keys.When:
+			le.When = r.parseTime(v)
+		case r.Keys.Level:
+			le.Level = parseLevel(v)
+		case r.Keys.Logger:
+			name = fmt.Sprint(v)
+		case r.Keys.Msg:
+			le.Msg = fmt.Sprint(v)
+		case r.Keys.File:
+			le.File = fmt.Sprint(v)
+		case r.Keys.Line:
+			if n, ok := v.(json.Number); ok {
+				i, _ := n.Int64()
+				le.Line = int(i)
+			}
+		case r.Keys.Func:
+			le.Func = fmt.Sprint(v)
+		case r.Keys.Trace:
+			le.Trace = fmt.Sprint(v)
+		default:
+			if le.Fields == nil {
+				le.Fields = make(map[string]interface{})
+			}
+			le.Fields[k] = v
+		}
+	}
+	le.Logger = r.log.GetLogger(name)
+
+	return le, nil
+}
+
+func (r *Reader) parseTime(v interface{}) time.Time {
+	switch t := v.(type) {
+	case string:
+		loc := r.Location
+		if loc == nil {
+			loc = time.Local
+		}
+		if r.TimeLayout != "" {
+			if tm, err := time.ParseInLocation(r.TimeLayout, t, loc); err == nil {
+				return tm
+			}
+		}
+		if tm, err := time.Parse(time.RFC3339Nano, t); err == nil {
+			return tm
+		}
+	case json.Number:
+		// unix timestamp in seconds
+		if f, err := t.Float64(); err == nil {
+			sec := int64(f)
+			return time.Unix(sec, int64((f-float64(sec))*1e9)).Round(time.Millisecond)
+		}
+	}
+	return time.Time{}
+}
+
+func parseLevel(v interface{}) log.Level {
+	switch l := v.(type) {
+	case string:
+		return log.ParseLevel(l)
+	case json.Number:
+		i, _ := l.Int64()
+		return log.Level(i)
+	}
+	return log.LevelNone
+}
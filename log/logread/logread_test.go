@@ -0,0 +1,275 @@
+package logread
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pandafw/pango/log"
+	"github.com/stretchr/testify/assert"
+)
+
+const testJSONFormat = `json:{"when": %t, "level": %l, "logger": %c, "msg": %m, "fields": %W}%n`
+
+func testWriteLogs(t *testing.T, path string, gzip bool) {
+	dir := filepath.Dir(path)
+	os.RemoveAll(dir)
+
+	lg := log.NewLog()
+	lg.SetLevel(log.LevelTrace)
+	lg.SetFormatter(log.NewLogFormatter(testJSONFormat))
+	lg.SetWriter(&log.FileWriter{Path: path, MaxSize: 200, Gzip: gzip})
+
+	for i := 1; i <= 10; i++ {
+		lg.GetLogger("web").Info("web ", i)
+		lg.GetLogger("db").With("n", i).Error("db ", i)
+
+		// make the modified time of the rotated files different
+		time.Sleep(time.Millisecond * 5)
+	}
+	lg.Close()
+}
+
+func newTestReader(path string) *Reader {
+	lr := NewReader(path)
+	lr.Keys.Logger = "logger"
+	return lr
+}
+
+func TestReaderReadAll(t *testing.T) {
+	for _, gzip := range []bool{false, true} {
+		path := "TestReaderReadAll/test.log"
+		testWriteLogs(t, path, gzip)
+
+		lr := newTestReader(path)
+		files, err := lr.Files()
+		assert.Nil(t, err)
+		assert.True(t, len(files) > 2, files)
+		if gzip {
+			assert.Equal(t, ".gz", filepath.Ext(files[0]))
+		}
+		assert.Equal(t, filepath.Clean(path), files[len(files)-1])
+
+		les, err := lr.ReadAll()
+		assert.Nil(t, err)
+		assert.Nil(t, lr.Close())
+
+		if assert.Equal(t, 20, len(les)) {
+			for i := 1; i <= 10; i++ {
+				le := les[(i-1)*2]
+				assert.Equal(t, "web", le.Logger.GetName())
+				assert.Equal(t, log.LevelInfo, le.Level)
+				assert.Equal(t, fmt.Sprint("web ", i), le.Msg)
+				assert.False(t, le.When.IsZero())
+
+				le = les[(i-1)*2+1]
+				assert.Equal(t, "db", le.Logger.GetName())
+				assert.Equal(t, log.LevelError, le.Level)
+				assert.Equal(t, fmt.Sprint("db ", i), le.Msg)
+				assert.Equal(t, map[string]interface{}{"n": json2Number(i)}, le.Fields["fields"])
+			}
+		}
+	}
+	os.RemoveAll("TestReaderReadAll")
+}
+
+func TestReaderFilesOthers(t *testing.T) {
+	path := "TestReaderFilesOthers/test.log"
+	testWriteLogs(t, path, false)
+
+	// not the rotated files
+	for _, name := range []string{"test-access.log", "test-01.log", "test-001.txt", "test-20060102-a.log", "test.log.bak"} {
+		ioutil.WriteFile(filepath.Join(filepath.Dir(path), name), []byte(`{"msg": "other"}`+"\n"), 0660)
+	}
+
+	lr := newTestReader(path)
+	files, err := lr.Files()
+	assert.Nil(t, err)
+	for _, f := range files {
+		assert.Regexp(t, `test(-\d{3})?\.log$`, f)
+	}
+
+	les, err := lr.ReadAll()
+	assert.Nil(t, err)
+	assert.Nil(t, lr.Close())
+	assert.Equal(t, 20, len(les))
+
+	os.RemoveAll("TestReaderFilesOthers")
+}
+
+func TestReaderCompressedAfterListed(t *testing.T) {
+	path := "TestReaderCompressedAfterListed/test.log"
+	testWriteLogs(t, path, false)
+
+	lr := newTestReader(path)
+	files, err := lr.Files()
+	assert.Nil(t, err)
+	lr.files = files
+
+	// compress the oldest rotated file after listed
+	src := files[0]
+	bs, _ := ioutil.ReadFile(src)
+	c := log.GetCompressor("gzip")
+	f, _ := os.Create(src + c.Ext())
+	w, _ := c.NewWriter(f)
+	w.Write(bs)
+	w.Close()
+	f.Close()
+	os.Remove(src)
+
+	les, err := lr.ReadAll()
+	assert.Nil(t, err)
+	assert.Nil(t, lr.Close())
+	if assert.Equal(t, 20, len(les)) {
+		assert.Equal(t, "web 1", les[0].Msg)
+	}
+
+	os.RemoveAll("TestReaderCompressedAfterListed")
+}
+
+func TestReaderFilter(t *testing.T) {
+	path := "TestReaderFilter/test.log"
+	testWriteLogs(t, path, true)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	lr := newTestReader(path)
+	lr.Filter.Level = log.LevelError
+	les, err := lr.ReadAll()
+	lr.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, 10, len(les))
+
+	lr = newTestReader(path)
+	lr.Filter.Logger = "w*"
+	les, err = lr.ReadAll()
+	lr.Close()
+	assert.Nil(t, err)
+	if assert.Equal(t, 10, len(les)) {
+		assert.Equal(t, "web 1", les[0].Msg)
+	}
+
+	lr = newTestReader(path)
+	lr.Filter.Logger = "?b"
+	les, err = lr.ReadAll()
+	lr.Close()
+	assert.Nil(t, err)
+	if assert.Equal(t, 10, len(les)) {
+		assert.Equal(t, "db 1", les[0].Msg)
+	}
+
+	// time range
+	lr = newTestReader(path)
+	all, _ := lr.ReadAll()
+	lr.Close()
+
+	lr = newTestReader(path)
+	lr.Filter.Start = all[4].When
+	lr.Filter.End = all[15].When
+	les, err = lr.ReadAll()
+	lr.Close()
+	assert.Nil(t, err)
+	for _, le := range les {
+		assert.False(t, le.When.Before(all[4].When))
+		assert.True(t, le.When.Before(all[15].When))
+	}
+}
+
+func TestReaderTail(t *testing.T) {
+	path := "TestReaderTail/test.log"
+	testWriteLogs(t, path, false)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	lr := newTestReader(path)
+	lr.Filter.Logger = "web"
+	les, err := lr.Tail(3)
+	lr.Close()
+
+	assert.Nil(t, err)
+	if assert.Equal(t, 3, len(les)) {
+		assert.Equal(t, "web 8", les[0].Msg)
+		assert.Equal(t, "web 9", les[1].Msg)
+		assert.Equal(t, "web 10", les[2].Msg)
+	}
+
+	lr = newTestReader(path)
+	les, err = lr.Tail(7)
+	lr.Close()
+
+	assert.Nil(t, err)
+	if assert.Equal(t, 7, len(les)) {
+		assert.Equal(t, "db 7", les[0].Msg)
+		assert.Equal(t, "web 8", les[1].Msg)
+		assert.Equal(t, "db 10", les[6].Msg)
+	}
+
+	lr = newTestReader(path)
+	lr.Filter.Logger = "db"
+	les, err = lr.Tail(100)
+	lr.Close()
+
+	assert.Nil(t, err)
+	if assert.Equal(t, 10, len(les)) {
+		assert.Equal(t, "db 1", les[0].Msg)
+		assert.Equal(t, "db 10", les[9].Msg)
+	}
+}
+
+func TestReaderDefaultKeys(t *testing.T) {
+	path := "TestReaderDefaultKeys/test.log"
+	os.RemoveAll(filepath.Dir(path))
+	defer os.RemoveAll(filepath.Dir(path))
+
+	lg := log.NewLog()
+	lg.SetFormatter(log.JSONFmtDefault)
+	lg.SetWriter(&log.FileWriter{Path: path})
+	lg.GetLogger("web").Warn("hello")
+	lg.Close()
+
+	lr := NewReader(path)
+	les, err := lr.ReadAll()
+	lr.Close()
+
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(les)) {
+		le := les[0]
+		assert.Equal(t, log.LevelWarn, le.Level)
+		assert.Equal(t, "hello", le.Msg)
+		assert.NotEqual(t, "", le.File)
+		assert.NotEqual(t, 0, le.Line)
+		assert.Nil(t, le.Fields)
+	}
+}
+
+func TestReaderParse(t *testing.T) {
+	os.RemoveAll("TestReaderParse")
+	defer os.RemoveAll("TestReaderParse")
+	os.MkdirAll("TestReaderParse", 0770)
+
+	ioutil.WriteFile("TestReaderParse/ecs.log", []byte(`not json
+{"@timestamp":"2020-01-02T03:04:05.006Z","ecs.version":"1.6.0","log.level":"warn","log.logger":"app","log.origin.file.line":10,"message":"msg","user.id":"u1"}
+`), 0660)
+
+	lr := NewReader("TestReaderParse/ecs.log")
+	lr.Keys = ECSKeys
+	les, err := lr.ReadAll()
+	lr.Close()
+
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(les)) {
+		le := les[0]
+		assert.Equal(t, time.Date(2020, 1, 2, 3, 4, 5, 6000000, time.UTC), le.When.UTC())
+		assert.Equal(t, log.LevelWarn, le.Level)
+		assert.Equal(t, "app", le.Logger.GetName())
+		assert.Equal(t, 10, le.Line)
+		assert.Equal(t, "msg", le.Msg)
+		assert.Equal(t, "u1", le.Fields["user.id"])
+	}
+}
+
+func json2Number(i int) interface{} {
+	return json.Number(fmt.Sprint(i))
+}
@@ -0,0 +1,344 @@
+package log
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pandafw/pango/str"
+	"github.com/pandafw/pango/vad"
+)
+
+// RedactMask the default mask of the sensitive data
+const RedactMask = "******"
+
+// DefaultSecretKeys the default sensitive key names of the "secret" redact rule
+var DefaultSecretKeys = []string{"password", "passwd", "pwd", "secret", "token", "apikey", "api_key", "authorization"}
+
+// RedactRule redact rule, it masks the sensitive texts of the string
+type RedactRule interface {
+	Redact(s, mask string) string
+}
+
+// keyMatcher a redact rule which masks the values of the sensitive keys (fields and props)
+type keyMatcher interface {
+	MatchKey(k string) bool
+}
+
+// RegexRedactRule regular expression redact rule.
+// It masks the sub-match Group (0: the whole match, -1: the first matched sub-match) of the texts matched by Regexp,
+// and accepted by Check (nil: accept all).
+type RegexRedactRule struct {
+	Regexp *regexp.Regexp
+	Group  int
+	Check  func(s string) bool
+}
+
+// NewRegexRedactRule create a regular expression redact rule by the pattern 'pattern'.
+// If the pattern has sub-matches, the first sub-match is masked, otherwise the whole match is masked.
+func NewRegexRedactRule(pattern string) (*RegexRedactRule, error) {
+	rx, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	rr := &RegexRedactRule{Regexp: rx}
+	if rx.NumSubexp() > 0 {
+		rr.Group = 1
+	}
+	return rr, nil
+}
+
+// Redact mask the matched texts of the string 's'
+func (rr *RegexRedactRule) Redact(s, mask string) string {
+	ms := rr.Regexp.FindAllStringSubmatchIndex(s, -1)
+	if len(ms) == 0 {
+		return s
+	}
+
+	sb := &strings.Builder{}
+	last, masked := 0, false
+	for _, m := range ms {
+		g := rr.Group
+		if g < 0 {
+			g = 0
+			for i := 1; i < len(m)/2; i++ {
+				if m[i*2] >= 0 {
+					g = i
+					break
+				}
+			}
+		}
+
+		b, e := m[g*2], m[g*2+1]
+		if b < 0 || (rr.Check != nil && !rr.Check(s[b:e])) {
+			continue
+		}
+		sb.WriteString(s[last:b])
+		sb.WriteString(mask)
+		last, masked = e, true
+	}
+	if !masked {
+		return s
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+// KeyRedactRule sensitive key name redact rule.
+// It masks the values of the fields and props which name contains one of the Keys (case-insensitive),
+// and the values of the "key=value", "key: value" texts.
+// The quoted value is masked until the closing quote, the unquoted value of "key=value" is masked until
+// a space or a delimiter (&,;), the unquoted value of the header style "key: value" is masked until the end
+// of the line or a delimiter (e.g. "Authorization: Bearer xxx").
+type KeyRedactRule struct {
+	Keys []string
+
+	rule *RegexRedactRule
+}
+
+// NewKeyRedactRule create a sensitive key name redact rule
+func NewKeyRedactRule(keys ...string) *KeyRedactRule {
+	kr := &KeyRedactRule{}
+
+	qks := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k != "" {
+			kr.Keys = append(kr.Keys, strings.ToLower(k))
+			qks = append(qks, regexp.QuoteMeta(k))
+		}
+	}
+
+	if len(qks) > 0 {
+		kr.rule = &RegexRedactRule{
+			Regexp: regexp.MustCompile(`(?i)(?:` + strings.Join(qks, "|") + `)\w*["']?\s*(?:` +
+				`[:=]\s*"([^"\r\n]+)"|` +
+				`[:=]\s*'([^'\r\n]+)'|` +
+				`=\s*["']?([^\s"'&,;]+)|` +
+				`:[ \t]*["']?([^\s"'&,;](?:[^\r\n"'&,;]*[^\s"'&,;])?))`),
+			Group: -1,
+		}
+	}
+	return kr
+}
+
+// MatchKey check the key name 'k' contains one of the sensitive keys
+func (kr *KeyRedactRule) MatchKey(k string) bool {
+	k = strings.ToLower(k)
+	for _, key := range kr.Keys {
+		if strings.Contains(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact mask the values of the "key=value", "key: value" texts of the string 's'
+func (kr *KeyRedactRule) Redact(s, mask string) string {
+	if kr.rule == nil {
+		return s
+	}
+	return kr.rule.Redact(s, mask)
+}
+
+// Redactor mask the sensitive data of the log event by the redact rules
+type Redactor struct {
+	Mask  string // the mask, default: RedactMask
+	Rules []RedactRule
+}
+
+// NewRedactor create a redactor with the rules
+func NewRedactor(rules ...RedactRule) *Redactor {
+	return &Redactor{Rules: rules}
+}
+
+// AddRule add the redact rule
+func (r *Redactor) AddRule(rule RedactRule) {
+	r.Rules = append(r.Rules, rule)
+}
+
+// AddRules add the registered redact rules by the names separated by space or comma, example: "email creditcard secret"
+func (r *Redactor) AddRules(names string) error {
+	for _, n := range str.FieldsAny(names, " ,") {
+		rule := GetRedactRule(n)
+		if rule == nil {
+			return fmt.Errorf("Invalid redact rule %q", n)
+		}
+		r.AddRule(rule)
+	}
+	return nil
+}
+
+// AddKeys add a sensitive key name redact rule with the keys
+func (r *Redactor) AddKeys(keys ...string) {
+	r.AddRule(NewKeyRedactRule(keys...))
+}
+
+// AddPattern add a regular expression redact rule with the pattern
+func (r *Redactor) AddPattern(pattern string) error {
+	rule, err := NewRegexRedactRule(pattern)
+	if err != nil {
+		return err
+	}
+	r.AddRule(rule)
+	return nil
+}
+
+func (r *Redactor) mask() string {
+	if r.Mask == "" {
+		return RedactMask
+	}
+	return r.Mask
+}
+
+// RedactString mask the sensitive texts of the string 's'
+func (r *Redactor) RedactString(s string) string {
+	mask := r.mask()
+	for _, rule := range r.Rules {
+		s = rule.Redact(s, mask)
+	}
+	return s
+}
+
+// RedactValue mask the value 'v' of the key 'k'.
+// The value of a sensitive key is masked entirely, the sensitive texts of a string value are masked.
+func (r *Redactor) RedactValue(k string, v interface{}) interface{} {
+	rv, _ := r.redactValue(k, v)
+	return rv
+}
+
+func (r *Redactor) redactValue(k string, v interface{}) (interface{}, bool) {
+	for _, rule := range r.Rules {
+		if km, ok := rule.(keyMatcher); ok && km.MatchKey(k) {
+			return r.mask(), true
+		}
+	}
+
+	if s, ok := v.(string); ok {
+		rs := r.RedactString(s)
+		return rs, rs != s
+	}
+	return v, false
+}
+
+// RedactMap mask the values of the map 'm', a copy of the map is returned if any value is masked
+func (r *Redactor) RedactMap(m map[string]interface{}) map[string]interface{} {
+	nm, _ := r.redactMap(m)
+	return nm
+}
+
+func (r *Redactor) redactMap(m map[string]interface{}) (map[string]interface{}, bool) {
+	var nm map[string]interface{}
+	for k, v := range m {
+		rv, ok := r.redactValue(k, v)
+		if !ok {
+			continue
+		}
+
+		if nm == nil {
+			nm = make(map[string]interface{}, len(m))
+			for k, v := range m {
+				nm[k] = v
+			}
+		}
+		nm[k] = rv
+	}
+
+	if nm == nil {
+		return m, false
+	}
+	return nm, true
+}
+
+// Redact mask the sensitive data of the event: the message, the error, the fields and the logger props.
+// The Fields map, the Error and the Logger of the event are replaced (not modified) if they contain sensitive data.
+func (r *Redactor) Redact(le *Event) {
+	le.Msg = r.RedactString(le.Msg)
+	le.Fields = r.RedactMap(le.Fields)
+
+	if le.Error != nil {
+		ei := &ErrorInfo{}
+		*ei = *le.Error
+		ei.Message = r.RedactString(ei.Message)
+		if len(ei.Causes) > 0 {
+			ei.Causes = make([]*ErrorCause, len(le.Error.Causes))
+			for i, c := range le.Error.Causes {
+				ei.Causes[i] = &ErrorCause{Type: c.Type, Message: r.RedactString(c.Message)}
+			}
+		}
+		le.Error = ei
+	}
+
+	if le.Logger != nil {
+		if props, ok := r.redactMap(le.Logger.GetProps()); ok {
+			le.Logger = &redactLogger{Logger: le.Logger, props: props}
+		}
+	}
+}
+
+// redactLogger a logger returns the redacted props
+type redactLogger struct {
+	Logger
+	props map[string]interface{}
+}
+
+// GetProp get the redacted logger property
+func (rl *redactLogger) GetProp(k string) interface{} {
+	return rl.props[k]
+}
+
+// GetProps get the redacted logger properties
+func (rl *redactLogger) GetProps() map[string]interface{} {
+	return rl.props
+}
+
+// RedactHook a hook masks the sensitive data of the event by the Redactor
+type RedactHook struct {
+	Redactor *Redactor
+}
+
+// NewRedactHook create a redact hook
+func NewRedactHook(r *Redactor) *RedactHook {
+	return &RedactHook{Redactor: r}
+}
+
+// Fire mask the sensitive data of the event
+func (rh *RedactHook) Fire(le *Event) bool {
+	rh.Redactor.Redact(le)
+	return true
+}
+
+var redactRules = make(map[string]RedactRule)
+
+// RegisterRedactRule register a redact rule by name
+func RegisterRedactRule(name string, rule RedactRule) {
+	redactRules[name] = rule
+}
+
+// GetRedactRule get the registered redact rule by name
+func GetRedactRule(name string) RedactRule {
+	return redactRules[name]
+}
+
+func init() {
+	RegisterRedactRule("email", &RegexRedactRule{
+		Regexp: regexp.MustCompile(strings.TrimSuffix(strings.TrimPrefix(vad.Email, "^"), "$")),
+	})
+	RegisterRedactRule("creditcard", &RegexRedactRule{
+		Regexp: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		Check:  vad.IsCreditCard,
+	})
+	RegisterRedactRule("secret", NewKeyRedactRule(DefaultSecretKeys...))
+
+	RegisterHook("redact", func(s string) (Hook, error) {
+		if s == "" {
+			s = "email,creditcard,secret"
+		}
+
+		r := NewRedactor()
+		if err := r.AddRules(s); err != nil {
+			return nil, err
+		}
+		return NewRedactHook(r), nil
+	})
+}
@@ -0,0 +1,107 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactRules(t *testing.T) {
+	cs := []struct {
+		rule string
+		s    string
+		w    string
+	}{
+		{"email", "mail to a.b+c@example.com, d@test.co.jp", "mail to ******, ******"},
+		{"email", "no mail @ here", "no mail @ here"},
+		{"creditcard", "card 4111 1111 1111 1111 paid", "card ****** paid"},
+		{"creditcard", "card 4111-1111-1111-1111, 5500000000000004", "card ******, ******"},
+		{"creditcard", "order 4111111111111112", "order 4111111111111112"},
+		{"secret", "login user=a password=abc&token=x1", "login user=a password=******&token=******"},
+		{"secret", `{"user":"a","Password":"p@ss","access_token": "t"}`, `{"user":"a","Password":"******","access_token": "******"}`},
+		{"secret", "Authorization: Bearer", "Authorization: ******"},
+		{"secret", "Authorization: Bearer abc123\nAccept: */*", "Authorization: ******\nAccept: */*"},
+		{"secret", "authorization:Basic dXNlcjpwYXNz  , x=1", "authorization:******  , x=1"},
+		{"secret", `token: "a b", user: x`, `token: "******", user: x`},
+		{"secret", `token='a b c'`, `token='******'`},
+		{"secret", `password=p1 next`, `password=****** next`},
+		{"secret", `{"token":"","password":"a\nb"}`, `{"token":"","password":"******"}`},
+	}
+
+	for i, c := range cs {
+		r := NewRedactor()
+		if assert.Nil(t, r.AddRules(c.rule)) {
+			assert.Equal(t, c.w, r.RedactString(c.s), fmt.Sprintf("[%d] %s", i, c.s))
+		}
+	}
+
+	assert.NotNil(t, NewRedactor().AddRules("email unknown"))
+}
+
+func TestRedactPattern(t *testing.T) {
+	r := NewRedactor()
+	r.Mask = "***"
+	assert.Nil(t, r.AddPattern(`\d{3}-\d{4}`))
+	assert.Nil(t, r.AddPattern(`ssn=(\d+)`))
+	assert.NotNil(t, r.AddPattern(`(`))
+
+	assert.Equal(t, "tel *** ssn=*** x", r.RedactString("tel 123-4567 ssn=987654 x"))
+}
+
+func TestRedactEvent(t *testing.T) {
+	r := NewRedactor()
+	r.AddRules("email")
+	r.AddKeys("pass")
+
+	log := NewLog()
+	lg := log.GetLogger("app")
+	lg.SetProps(map[string]interface{}{"host": "h", "mail": "a@b.com", "passcode": 1})
+	lg = lg.With("k", "v", "password", "x", "n", []int{1})
+
+	le := newEvent(lg, LevelError, "user a@b.com pass=xyz")
+	le.Error = NewErrorInfo(fmt.Errorf("wrap: %w", errors.New("c@d.com")))
+
+	ei := le.Error
+	r.Redact(le)
+
+	assert.Equal(t, "user ****** pass=******", le.Msg)
+	assert.Equal(t, map[string]interface{}{"k": "v", "password": "******", "n": []int{1}}, le.Fields)
+	assert.Equal(t, "wrap: ******", le.Error.Message)
+	assert.Equal(t, "******", le.Error.Causes[0].Message)
+	assert.Equal(t, "app", le.Logger.GetName())
+	assert.Equal(t, map[string]interface{}{"host": "h", "mail": "******", "passcode": "******"}, le.Logger.GetProps())
+	assert.Equal(t, "******", le.Logger.GetProp("mail"))
+
+	// the origin values are not modified
+	assert.Equal(t, "x", lg.GetFields()["password"])
+	assert.Equal(t, "a@b.com", lg.GetProp("mail"))
+	assert.Equal(t, "c@d.com", ei.Causes[0].Message)
+
+	// not replaced if no sensitive data
+	le = newEvent(log.GetLogger("x"), LevelInfo, "ok")
+	lgr := le.Logger
+	r.Redact(le)
+	assert.Equal(t, "ok", le.Msg)
+	assert.True(t, lgr == le.Logger)
+}
+
+func TestRedactHook(t *testing.T) {
+	bw := &testBatchWriter{}
+	log := NewLog()
+	log.SetWriter(bw)
+
+	hs, err := NewLogHooks("redact")
+	if !assert.Nil(t, err) {
+		return
+	}
+	log.SetHooks(hs...)
+
+	log.Info("a@b.com pwd=1")
+	log.Info("card 4111111111111111")
+	assert.Equal(t, []string{"****** pwd=******", "card ******"}, bw.msgs)
+
+	_, err = NewLogHooks("redact:unknown")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,48 @@
+package log
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// CloseOnSignal close the log (write the queued events of the async mode, flush and close the writers)
+// when one of the signals 'sigs' (default: SIGINT, SIGTERM) is received,
+// and then exit the process with the code 128 + signal number.
+// It returns a function to stop handling the signals.
+// If the application handles the signals itself (graceful shutdown), call log.Close() before exit instead.
+func (log *Log) CloseOnSignal(sigs ...os.Signal) func() {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc, sigs...)
+
+	done := make(chan struct{})
+	go log.closeOnSignal(sc, done)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sc)
+			close(done)
+		})
+	}
+}
+
+// closeOnSignal wait for the signal, close the log and exit
+func (log *Log) closeOnSignal(sc <-chan os.Signal, done <-chan struct{}) {
+	select {
+	case sig := <-sc:
+		log.Close()
+
+		code := 1
+		if s, ok := sig.(syscall.Signal); ok {
+			code = 128 + int(s)
+		}
+		osExit(code)
+	case <-done:
+	}
+}
@@ -0,0 +1,214 @@
+// Package logtest capture the log events for the assertions in tests.
+// Usage:
+//
+//	func TestXxx(t *testing.T) {
+//		rec := logtest.Capture(t, log.Default())
+//
+//		... // code using log.GetLogger("web")
+//
+//		rec.AssertContains(t, log.LevelError, "web", "connection refused")
+//		rec.AssertNotContains(t, log.LevelNone, "", "password")
+//	}
+//
+package logtest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pandafw/pango/log"
+	"github.com/pandafw/pango/str/wildcard"
+)
+
+// Recorder implements log.Writer.
+// It records the copies of all written events (the events written to the log writer are pooled and cleared after writing).
+type Recorder struct {
+	log    *log.Log
+	mutex  sync.Mutex
+	events []*log.Event
+}
+
+// NewRecorder create a Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Capture create a Recorder and set it as the writer of the log 'lg' (nil: log.Default()).
+// The previous writer of the log is restored (not closed) when the test 't' and its subtests complete.
+func Capture(t testing.TB, lg *log.Log) *Recorder {
+	if lg == nil {
+		lg = log.Default()
+	}
+
+	r := &Recorder{log: lg}
+	ow := lg.SwapWriter(r)
+	t.Cleanup(func() {
+		lg.SwapWriter(ow)
+	})
+	return r
+}
+
+// NewLog create a log.Log with a Recorder as the writer
+func NewLog() (*log.Log, *Recorder) {
+	lg := log.NewLog()
+	r := &Recorder{log: lg}
+	lg.SetWriter(r)
+	return lg, r
+}
+
+// Write record a copy of the event
+func (r *Recorder) Write(le *log.Event) {
+	ce := &log.Event{}
+	*ce = *le
+
+	if len(le.Fields) > 0 {
+		ce.Fields = make(map[string]interface{}, len(le.Fields))
+		for k, v := range le.Fields {
+			ce.Fields[k] = v
+		}
+	}
+
+	r.mutex.Lock()
+	r.events = append(r.events, ce)
+	r.mutex.Unlock()
+}
+
+// Flush do nothing
+func (r *Recorder) Flush() {
+}
+
+// Close do nothing
+func (r *Recorder) Close() {
+}
+
+// Reset clear the recorded events
+func (r *Recorder) Reset() {
+	r.flush()
+
+	r.mutex.Lock()
+	r.events = nil
+	r.mutex.Unlock()
+}
+
+// flush flush the queued events of the captured log (async mode)
+func (r *Recorder) flush() {
+	if r.log != nil {
+		r.log.Flush()
+	}
+}
+
+// Events return the recorded events
+func (r *Recorder) Events() []*log.Event {
+	r.flush()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	les := make([]*log.Event, len(r.events))
+	copy(les, r.events)
+	return les
+}
+
+// Messages return the messages of the recorded events
+func (r *Recorder) Messages() []string {
+	les := r.Events()
+	ms := make([]string, len(les))
+	for i, le := range les {
+		ms[i] = le.Msg
+	}
+	return ms
+}
+
+// Find return the recorded events which level is 'lvl' (LevelNone: any level),
+// which logger name matches 'name' ("": any logger, the logger name or a wildcard pattern, e.g. "web.*"),
+// and which message contains 'msg'.
+func (r *Recorder) Find(lvl log.Level, name string, msg string) []*log.Event {
+	var les []*log.Event
+	for _, le := range r.Events() {
+		if Match(le, lvl, name, msg) {
+			les = append(les, le)
+		}
+	}
+	return les
+}
+
+// Count return the count of the recorded events matched by Find(lvl, name, msg)
+func (r *Recorder) Count(lvl log.Level, name string, msg string) int {
+	return len(r.Find(lvl, name, msg))
+}
+
+// Contains check the recorded events contains a event matched by Find(lvl, name, msg)
+func (r *Recorder) Contains(lvl log.Level, name string, msg string) bool {
+	return r.Count(lvl, name, msg) > 0
+}
+
+// AssertContains assert the recorded events contains a event matched by Find(lvl, name, msg)
+func (r *Recorder) AssertContains(t testing.TB, lvl log.Level, name string, msg string) bool {
+	t.Helper()
+
+	if r.Contains(lvl, name, msg) {
+		return true
+	}
+	t.Errorf("Should contain a %s event of logger %q with message %q, recorded:\n%s", lvl, name, msg, r.dump())
+	return false
+}
+
+// AssertNotContains assert the recorded events does not contain any event matched by Find(lvl, name, msg)
+func (r *Recorder) AssertNotContains(t testing.TB, lvl log.Level, name string, msg string) bool {
+	t.Helper()
+
+	les := r.Find(lvl, name, msg)
+	if len(les) == 0 {
+		return true
+	}
+	t.Errorf("Should not contain a %s event of logger %q with message %q, found: %s %q", lvl, name, msg, les[0].Level, les[0].Msg)
+	return false
+}
+
+// AssertCount assert the count of the recorded events matched by Find(lvl, name, msg) is 'n'
+func (r *Recorder) AssertCount(t testing.TB, n int, lvl log.Level, name string, msg string) bool {
+	t.Helper()
+
+	c := r.Count(lvl, name, msg)
+	if c == n {
+		return true
+	}
+	t.Errorf("Should have %d %s events of logger %q with message %q, actual: %d, recorded:\n%s", n, lvl, name, msg, c, r.dump())
+	return false
+}
+
+// dump return the recorded events as text lines
+func (r *Recorder) dump() string {
+	var sb strings.Builder
+	for _, le := range r.Events() {
+		sb.WriteString("\t")
+		sb.WriteString(le.Level.String())
+		sb.WriteString(" ")
+		sb.WriteString(loggerName(le))
+		sb.WriteString(" - ")
+		sb.WriteString(le.Msg)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// Match check the event's level is 'lvl' (LevelNone: any level),
+// the logger name matches 'name' ("": any logger, the logger name or a wildcard pattern, e.g. "web.*"),
+// and the message contains 'msg'.
+func Match(le *log.Event, lvl log.Level, name string, msg string) bool {
+	if lvl != log.LevelNone && le.Level != lvl {
+		return false
+	}
+	if name != "" && !wildcard.Match(name, loggerName(le)) {
+		return false
+	}
+	return strings.Contains(le.Msg, msg)
+}
+
+func loggerName(le *log.Event) string {
+	if le.Logger == nil {
+		return ""
+	}
+	return le.Logger.GetName()
+}
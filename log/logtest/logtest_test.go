@@ -0,0 +1,121 @@
+package logtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pandafw/pango/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeT records the errors of the assertions
+type fakeT struct {
+	testing.TB
+	errors []string
+}
+
+func (ft *fakeT) Helper() {
+}
+
+func (ft *fakeT) Errorf(format string, args ...interface{}) {
+	ft.errors = append(ft.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRecorder(t *testing.T) {
+	lg, rec := NewLog()
+
+	lg.GetLogger("web").With("user", "admin").Info("login ok")
+	lg.GetLogger("web.api").Error("connection refused")
+	lg.GetLogger("sql").Warn("slow query")
+
+	assert.Equal(t, []string{"login ok", "connection refused", "slow query"}, rec.Messages())
+
+	les := rec.Events()
+	assert.Equal(t, "web", les[0].Logger.GetName())
+	assert.Equal(t, map[string]interface{}{"user": "admin"}, les[0].Fields)
+	assert.Equal(t, log.LevelError, les[1].Level)
+
+	assert.True(t, rec.Contains(log.LevelInfo, "web", "login"))
+	assert.True(t, rec.Contains(log.LevelError, "web*", "refused"))
+	assert.True(t, rec.Contains(log.LevelNone, "", "slow"))
+	assert.False(t, rec.Contains(log.LevelError, "web", "refused"))
+	assert.False(t, rec.Contains(log.LevelInfo, "sql", ""))
+
+	assert.Equal(t, 2, rec.Count(log.LevelNone, "web*", ""))
+	assert.Equal(t, 1, rec.Count(log.LevelNone, "*.api", ""))
+	assert.Equal(t, 1, rec.Count(log.LevelNone, "s?l", ""))
+	assert.Equal(t, 1, len(rec.Find(log.LevelWarn, "", "")))
+
+	rec.AssertContains(t, log.LevelWarn, "sql", "slow query")
+	rec.AssertNotContains(t, log.LevelNone, "", "password")
+	rec.AssertCount(t, 3, log.LevelNone, "", "")
+
+	rec.Reset()
+	assert.Equal(t, 0, len(rec.Events()))
+}
+
+func TestRecorderAssertFailed(t *testing.T) {
+	lg, rec := NewLog()
+	lg.Info("hello")
+
+	ft := &fakeT{}
+	assert.False(t, rec.AssertContains(ft, log.LevelError, "", "hello"))
+	assert.False(t, rec.AssertNotContains(ft, log.LevelInfo, "", "hello"))
+	assert.False(t, rec.AssertCount(ft, 2, log.LevelNone, "", ""))
+
+	if assert.Equal(t, 3, len(ft.errors)) {
+		assert.Contains(t, ft.errors[0], "INFO  - hello")
+		assert.Contains(t, ft.errors[1], `found: INFO "hello"`)
+		assert.Contains(t, ft.errors[2], "actual: 1")
+	}
+}
+
+func TestRecorderAsync(t *testing.T) {
+	lg, rec := NewLog()
+	lg.Async(100)
+	defer lg.Close()
+
+	for i := 0; i < 10; i++ {
+		lg.Info(i)
+	}
+
+	rec.AssertCount(t, 10, log.LevelInfo, "", "")
+}
+
+// closeWriter records the count of the written events and the closed state
+type closeWriter struct {
+	count  int
+	closed bool
+}
+
+func (cw *closeWriter) Write(le *log.Event) {
+	cw.count++
+}
+
+func (cw *closeWriter) Flush() {
+}
+
+func (cw *closeWriter) Close() {
+	cw.closed = true
+}
+
+func TestCapture(t *testing.T) {
+	lg := log.NewLog()
+	cw := &closeWriter{}
+	lg.SetWriter(cw)
+
+	t.Run("capture", func(t *testing.T) {
+		rec := Capture(t, lg)
+		lg.Info("captured")
+
+		rec.AssertCount(t, 1, log.LevelInfo, "", "captured")
+		assert.False(t, cw.closed)
+		assert.Equal(t, 0, cw.count)
+	})
+
+	assert.Equal(t, cw, lg.GetWriter())
+	assert.False(t, cw.closed)
+
+	lg.Info("restored")
+	assert.Equal(t, 1, cw.count)
+}
@@ -0,0 +1,90 @@
+// Package logwatch watch the log configuration file and reload the log configuration on change.
+// Usage:
+//
+//	lw := logwatch.NewLogWatcher(log.Default(), "log.ini")
+//	if err := lw.Start(); err != nil {
+//		...
+//	}
+//	defer lw.Stop()
+//
+package logwatch
+
+import (
+	"github.com/pandafw/pango/iox/fswatch"
+	"github.com/pandafw/pango/log"
+)
+
+// LogWatcher watch the log configuration file and reload the log configuration on change.
+// If the changed configuration file is invalid, the error is logged and the current configuration is kept.
+type LogWatcher struct {
+	Log    *log.Log   // the log to configure
+	Path   string     // the log configuration file
+	Logger log.Logger // the error logger, default is Log
+
+	fw *fswatch.FileWatcher
+}
+
+// NewLogWatcher create a LogWatcher
+func NewLogWatcher(lg *log.Log, path string) *LogWatcher {
+	return &LogWatcher{Log: lg, Path: path}
+}
+
+// Watch configure the log 'lg' by the configuration file 'path' and start watching it
+func Watch(lg *log.Log, path string) (*LogWatcher, error) {
+	lw := NewLogWatcher(lg, path)
+	if err := lg.Config(path); err != nil {
+		return nil, err
+	}
+	if err := lw.Start(); err != nil {
+		return nil, err
+	}
+	return lw, nil
+}
+
+// Start start watching the log configuration file
+func (lw *LogWatcher) Start() error {
+	if lw.fw != nil {
+		return nil
+	}
+
+	// register the path before the watch goroutine is started
+	fw := fswatch.NewFileWatcher()
+	if err := fw.Add(lw.Path, fswatch.OpWrite|fswatch.OpCreate, lw.reload); err != nil {
+		return err
+	}
+
+	if err := fw.Start(); err != nil {
+		fw.Stop()
+		return err
+	}
+
+	lw.fw = fw
+	return nil
+}
+
+// Stop stop watching the log configuration file
+func (lw *LogWatcher) Stop() error {
+	fw := lw.fw
+	if fw == nil {
+		return nil
+	}
+
+	lw.fw = nil
+	return fw.Stop()
+}
+
+func (lw *LogWatcher) logger() log.Logger {
+	if lw.Logger != nil {
+		return lw.Logger
+	}
+	return lw.Log
+}
+
+// reload reload the log configuration
+func (lw *LogWatcher) reload(path string, op fswatch.Op) {
+	if err := lw.Log.Config(path); err != nil {
+		lw.logger().Errorf("Failed to reload log configuration %q: %v", path, err)
+		return
+	}
+	lw.logger().Infof("Reload log configuration %q", path)
+}
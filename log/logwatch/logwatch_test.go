@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -86,3 +87,62 @@ func TestLogConfigFile1toFile2(t *testing.T) {
 		return
 	}
 }
+
+func TestLogWatcherInvalidConfig(t *testing.T) {
+	os.RemoveAll("conftest2")
+	defer os.RemoveAll("conftest2")
+
+	os.MkdirAll("conftest2", 0777)
+
+	path := "conftest2/log.json"
+	config := func(name string) {
+		js := `{"async": 100, "writer": [{"_": "file", "path": "conftest2/logs/` + name + `.log", "format": "%l - %m%n"}]}`
+		assert.Nil(t, ioutil.WriteFile(path, []byte(js), 0666))
+	}
+
+	config("a")
+
+	lg := log.NewLog()
+	lw, err := Watch(lg, path)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer lw.Stop()
+
+	waitWriter := func(name string) bool {
+		for i := 0; i < 10; i++ {
+			if fw, ok := lg.GetWriter().(*log.FileWriter); ok && fw.Path == "conftest2/logs/"+name+".log" {
+				return true
+			}
+			fmt.Println(strconv.Itoa(i) + " - Sleep 1s for log config reload")
+			time.Sleep(time.Second * 1)
+		}
+		return false
+	}
+
+	lg.Info("a1")
+
+	// invalid configuration, the current configuration is kept
+	time.Sleep(time.Second * 1)
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"async": 100, "writer": [{"_": "unknown"}]}`), 0666))
+	time.Sleep(time.Second * 3)
+
+	lg.Info("a2")
+
+	// valid configuration
+	config("b")
+	if !assert.True(t, waitWriter("b")) {
+		return
+	}
+
+	lg.Info("b1")
+	lg.Close()
+
+	bs, _ := ioutil.ReadFile("conftest2/logs/a.log")
+	assert.True(t, strings.HasPrefix(string(bs), "INFO - a1"+iox.EOL), string(bs))
+	assert.Contains(t, string(bs), "ERROR - Failed to reload log configuration")
+	assert.Contains(t, string(bs), "INFO - a2"+iox.EOL)
+
+	bs, _ = ioutil.ReadFile("conftest2/logs/b.log")
+	assert.Equal(t, `INFO - Reload log configuration "`+path+`"`+iox.EOL+"INFO - b1"+iox.EOL, string(bs))
+}
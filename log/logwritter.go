@@ -15,6 +15,16 @@ type Writer interface {
 	Flush()
 }
 
+// BatchWriter defines the behavior of a log writer which can write events in batch.
+type BatchWriter interface {
+	WriteBatch(les []*Event)
+}
+
+// writerWrapper a writer wraps another writer (AsyncWriter)
+type writerWrapper interface {
+	Unwrap() Writer
+}
+
 // WriterCreator writer create function
 type WriterCreator func() Writer
 
@@ -33,15 +43,50 @@ func CreateWriter(name string) Writer {
 	return nil
 }
 
-// ConfigWriter config the writer by the configuration map 'c'
+// ConfigWriter config the writer by the configuration map 'c'.
+// For a writer wraps another writer (AsyncWriter), the property "writer" is set first,
+// the properties with the prefix "writer." are set to the wrapped writer (without the prefix).
 func ConfigWriter(w Writer, c map[string]interface{}) error {
-	for k, v := range c {
-		if k != "_" && k != "" && v != nil {
-			if err := setWriterProp(w, k, v); err != nil {
+	ww, wrapper := w.(writerWrapper)
+	if wrapper {
+		if v, ok := c["writer"]; ok && v != nil {
+			if err := setWriterProp(w, "writer", v); err != nil {
 				return err
 			}
 		}
 	}
+
+	var wc map[string]interface{}
+	for k, v := range c {
+		if k == "_" || k == "" || v == nil {
+			continue
+		}
+
+		if wrapper {
+			if k == "writer" {
+				continue
+			}
+			if strings.HasPrefix(k, "writer.") {
+				if wc == nil {
+					wc = make(map[string]interface{})
+				}
+				wc[k[len("writer."):]] = v
+				continue
+			}
+		}
+
+		if err := setWriterProp(w, k, v); err != nil {
+			return err
+		}
+	}
+
+	if len(wc) > 0 {
+		iw := ww.Unwrap()
+		if iw == nil {
+			return fmt.Errorf("Missing the wrapped writer of %T", w)
+		}
+		return ConfigWriter(iw, wc)
+	}
 	return nil
 }
 
@@ -64,7 +109,12 @@ func setWriterProp(w Writer, k string, v interface{}) (err error) {
 			return err
 		}
 
-		m.Call([]reflect.Value{reflect.ValueOf(i)})
+		rs := m.Call([]reflect.Value{reflect.ValueOf(i)})
+		if len(rs) > 0 {
+			if err, ok := rs[len(rs)-1].Interface().(error); ok {
+				return err
+			}
+		}
 		return nil
 	}
 
@@ -0,0 +1,180 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pandafw/pango/str/wildcard"
+)
+
+// memoryEntry a kept event with the write sequence
+type memoryEntry struct {
+	seq uint64
+	evt *Event
+}
+
+// memoryRing a fixed size ring buffer of the kept events
+type memoryRing struct {
+	entries []memoryEntry
+	next    int
+	full    bool
+}
+
+func (mr *memoryRing) push(me memoryEntry) {
+	mr.entries[mr.next] = me
+	mr.next++
+	if mr.next >= len(mr.entries) {
+		mr.next = 0
+		mr.full = true
+	}
+}
+
+// list return the entries in write order (oldest first)
+func (mr *memoryRing) list() []memoryEntry {
+	if !mr.full {
+		return mr.entries[:mr.next]
+	}
+	es := make([]memoryEntry, 0, len(mr.entries))
+	es = append(es, mr.entries[mr.next:]...)
+	es = append(es, mr.entries[:mr.next]...)
+	return es
+}
+
+// MemoryWriter implements Writer.
+// It keeps the last Size events (or the last Size events of each level if PerLevel is true) in memory.
+// The kept events can be retrieved by Snapshot() or Query().
+type MemoryWriter struct {
+	Size     int    // max count of the kept events, default: 1000
+	PerLevel bool   // keep the last Size events for each level
+	Logfil   Filter // log filter
+
+	wstat writerStat // the writer statistics
+	mutex sync.RWMutex
+	seq   uint64
+	rings [LevelTrace + 1]*memoryRing
+}
+
+// NewMemoryWriter create a memory writer which keeps the last 'size' events
+func NewMemoryWriter(size int) *MemoryWriter {
+	return &MemoryWriter{Size: size}
+}
+
+// SetFilter set the log filter
+func (mw *MemoryWriter) SetFilter(filter string) error {
+	f, err := ParseLogFilter(filter)
+	if err != nil {
+		return fmt.Errorf("MemoryWriter - %v", err)
+	}
+	mw.Logfil = f
+	return nil
+}
+
+// stats return the statistics of the writer
+func (mw *MemoryWriter) stats() *writerStat {
+	return &mw.wstat
+}
+
+// Write keep a copy of the event in the ring buffer
+func (mw *MemoryWriter) Write(le *Event) {
+	if reject(&mw.wstat, mw.Logfil, le) {
+		return
+	}
+
+	// copy the event, because the event will be put back to the pool
+	ce := &Event{}
+	*ce = *le
+
+	mw.mutex.Lock()
+	defer mw.mutex.Unlock()
+
+	i := 0
+	if mw.PerLevel && le.Level <= LevelTrace {
+		i = int(le.Level)
+	}
+
+	mr := mw.rings[i]
+	if mr == nil {
+		size := mw.Size
+		if size <= 0 {
+			size = 1000
+		}
+		mr = &memoryRing{entries: make([]memoryEntry, size)}
+		mw.rings[i] = mr
+	}
+
+	mw.seq++
+	mr.push(memoryEntry{seq: mw.seq, evt: ce})
+}
+
+// Snapshot return all the kept events in write order (oldest first)
+func (mw *MemoryWriter) Snapshot() []*Event {
+	return mw.Query(LevelNone, "", 0)
+}
+
+// Query return the last 'limit' (0: no limit) kept events in write order (oldest first),
+// which level is equal or higher than 'lvl' (LevelNone: all levels),
+// and which logger name matches 'name' ("": all loggers, the logger name or a wildcard pattern, e.g. "web.*").
+func (mw *MemoryWriter) Query(lvl Level, name string, limit int) []*Event {
+	mw.mutex.RLock()
+	var es []memoryEntry
+	for _, mr := range mw.rings {
+		if mr != nil {
+			es = append(es, mr.list()...)
+		}
+	}
+	mw.mutex.RUnlock()
+
+	sort.Slice(es, func(i, j int) bool {
+		return es[i].seq < es[j].seq
+	})
+
+	les := make([]*Event, 0, len(es))
+	for _, me := range es {
+		le := me.evt
+		if lvl != LevelNone && le.Level > lvl {
+			continue
+		}
+		if name != "" && !matchLoggerName(le.Logger, name) {
+			continue
+		}
+		les = append(les, le)
+	}
+
+	if limit > 0 && len(les) > limit {
+		les = les[len(les)-limit:]
+	}
+	return les
+}
+
+// Clear clear all the kept events
+func (mw *MemoryWriter) Clear() {
+	mw.mutex.Lock()
+	for i := range mw.rings {
+		mw.rings[i] = nil
+	}
+	mw.mutex.Unlock()
+}
+
+// Flush do nothing
+func (mw *MemoryWriter) Flush() {
+}
+
+// Close do nothing
+func (mw *MemoryWriter) Close() {
+}
+
+// matchLoggerName check the logger name matches the 'name' (wildcard pattern)
+func matchLoggerName(logger Logger, name string) bool {
+	ln := ""
+	if logger != nil {
+		ln = logger.GetName()
+	}
+	return wildcard.Match(name, ln)
+}
+
+func init() {
+	RegisterWriter("memory", func() Writer {
+		return &MemoryWriter{Size: 1000}
+	})
+}
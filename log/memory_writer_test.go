@@ -0,0 +1,95 @@
+package log
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func memoryMsgs(les []*Event) []string {
+	ms := make([]string, 0, len(les))
+	for _, le := range les {
+		ms = append(ms, le.Msg)
+	}
+	return ms
+}
+
+func TestMemoryWriter(t *testing.T) {
+	mw := NewMemoryWriter(3)
+
+	log := NewLog()
+	log.SetWriter(mw)
+	for i := 1; i <= 5; i++ {
+		log.Info(fmt.Sprint("info", i))
+	}
+
+	les := mw.Snapshot()
+	assert.Equal(t, []string{"info3", "info4", "info5"}, memoryMsgs(les))
+	assert.Equal(t, LevelInfo, les[0].Level)
+	assert.Equal(t, "", les[0].Logger.GetName())
+
+	// the kept events are copies
+	log.Info("info6")
+	assert.Equal(t, []string{"info3", "info4", "info5"}, memoryMsgs(les))
+	assert.Equal(t, []string{"info4", "info5", "info6"}, memoryMsgs(mw.Snapshot()))
+
+	mw.Clear()
+	assert.Equal(t, 0, len(mw.Snapshot()))
+}
+
+func TestMemoryWriterPerLevel(t *testing.T) {
+	mw := &MemoryWriter{Size: 2, PerLevel: true}
+
+	log := NewLog()
+	log.SetWriter(mw)
+	log.Error("error1")
+	log.Info("info1")
+	log.Info("info2")
+	log.Info("info3")
+	log.Warn("warn1")
+	log.Info("info4")
+
+	assert.Equal(t, []string{"error1", "info3", "warn1", "info4"}, memoryMsgs(mw.Snapshot()))
+	assert.Equal(t, []string{"error1", "warn1"}, memoryMsgs(mw.Query(LevelWarn, "", 0)))
+	assert.Equal(t, []string{"warn1", "info4"}, memoryMsgs(mw.Query(LevelInfo, "", 2)))
+}
+
+func TestMemoryWriterQueryLogger(t *testing.T) {
+	mw := NewMemoryWriter(10)
+
+	log := NewLog()
+	log.SetWriter(mw)
+	log.GetLogger("web").Info("web1")
+	log.GetLogger("web.api").Info("api1")
+	log.GetLogger("sql").Info("sql1")
+
+	assert.Equal(t, []string{"web1"}, memoryMsgs(mw.Query(LevelNone, "web", 0)))
+	assert.Equal(t, []string{"web1", "api1"}, memoryMsgs(mw.Query(LevelNone, "web*", 0)))
+	assert.Equal(t, []string{"sql1"}, memoryMsgs(mw.Query(LevelInfo, "sql", 0)))
+	assert.Equal(t, []string{"api1"}, memoryMsgs(mw.Query(LevelNone, "*.api", 0)))
+	assert.Equal(t, []string{"web1"}, memoryMsgs(mw.Query(LevelNone, "w?b", 0)))
+}
+
+func TestMemoryWriterConfig(t *testing.T) {
+	w := CreateWriter("memory")
+	err := ConfigWriter(w, map[string]interface{}{
+		"size":     "5",
+		"perLevel": "true",
+		"filter":   "level:warn",
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	mw := w.(*MemoryWriter)
+	assert.Equal(t, 5, mw.Size)
+	assert.True(t, mw.PerLevel)
+
+	log := NewLog()
+	log.SetWriter(mw)
+	log.Info("info")
+	log.Error("error")
+
+	assert.Equal(t, []string{"error"}, memoryMsgs(mw.Snapshot()))
+}
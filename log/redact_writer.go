@@ -0,0 +1,105 @@
+package log
+
+import (
+	"fmt"
+
+	"github.com/pandafw/pango/str"
+)
+
+// RedactWriter implements Writer.
+// It wraps a writer, masks the sensitive data (message, error, fields and logger props) of the event copy
+// by the Redactor, and writes the redacted event to the wrapped writer.
+type RedactWriter struct {
+	Writer   Writer    // the wrapped writer
+	Redactor *Redactor // the redactor
+}
+
+// NewRedactWriter create a redact writer wraps the writer 'w'
+func NewRedactWriter(w Writer, r *Redactor) *RedactWriter {
+	return &RedactWriter{Writer: w, Redactor: r}
+}
+
+// SetWriter create the wrapped writer by the registered writer name
+func (rw *RedactWriter) SetWriter(name string) error {
+	w := CreateWriter(name)
+	if w == nil {
+		return fmt.Errorf("RedactWriter - Invalid writer name: %v", name)
+	}
+	rw.Writer = w
+	return nil
+}
+
+// SetRules add the registered redact rules (email, creditcard, secret) separated by space or comma
+func (rw *RedactWriter) SetRules(rules string) error {
+	if err := rw.redactor().AddRules(rules); err != nil {
+		return fmt.Errorf("RedactWriter - %v", err)
+	}
+	return nil
+}
+
+// SetKeys add the sensitive key names separated by space, comma or semicolon
+func (rw *RedactWriter) SetKeys(keys string) {
+	rw.redactor().AddKeys(str.FieldsAny(keys, " ,;")...)
+}
+
+// SetPattern add a regular expression redact rule
+func (rw *RedactWriter) SetPattern(pattern string) error {
+	if err := rw.redactor().AddPattern(pattern); err != nil {
+		return fmt.Errorf("RedactWriter - Invalid pattern: %v", err)
+	}
+	return nil
+}
+
+// SetMask set the mask of the sensitive data
+func (rw *RedactWriter) SetMask(mask string) {
+	rw.redactor().Mask = mask
+}
+
+func (rw *RedactWriter) redactor() *Redactor {
+	if rw.Redactor == nil {
+		rw.Redactor = NewRedactor()
+	}
+	return rw.Redactor
+}
+
+// Unwrap return the wrapped writer
+func (rw *RedactWriter) Unwrap() Writer {
+	return rw.Writer
+}
+
+// Write copy the event, mask the sensitive data and write it to the wrapped writer
+func (rw *RedactWriter) Write(le *Event) {
+	if rw.Writer == nil {
+		return
+	}
+	if rw.Redactor == nil {
+		rw.Writer.Write(le)
+		return
+	}
+
+	// copy the event, because the event may be written to other writers
+	ce := &Event{}
+	*ce = *le
+	rw.Redactor.Redact(ce)
+	rw.Writer.Write(ce)
+}
+
+// Flush flush the wrapped writer
+func (rw *RedactWriter) Flush() {
+	if rw.Writer != nil {
+		rw.Writer.Flush()
+	}
+}
+
+// Close close the wrapped writer
+func (rw *RedactWriter) Close() {
+	if rw.Writer != nil {
+		rw.Writer.Close()
+	}
+}
+
+func init() {
+	RegisterWriter("redact", func() Writer {
+		return &RedactWriter{}
+	})
+}
@@ -0,0 +1,53 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactWriter(t *testing.T) {
+	bw, rbw := &testBatchWriter{}, &testBatchWriter{}
+
+	r := NewRedactor()
+	r.AddRules("secret")
+
+	log := NewLog()
+	log.SetWriter(NewMultiWriter(bw, NewRedactWriter(rbw, r)))
+	log.Info("token=abc")
+	log.Close()
+
+	assert.Equal(t, []string{"token=abc"}, bw.msgs)
+	assert.Equal(t, []string{"token=******"}, rbw.msgs)
+	assert.True(t, rbw.closed)
+}
+
+func testRedactConfig(t *testing.T, file string) {
+	log := NewLog()
+	if !assert.Nil(t, log.Config(file)) {
+		return
+	}
+
+	rw, ok := log.GetWriter().(*RedactWriter)
+	if !assert.True(t, ok) {
+		return
+	}
+	mw, ok := rw.Writer.(*MemoryWriter)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, 10, mw.Size)
+	assert.Equal(t, "#", rw.Redactor.Mask)
+
+	log.Info("a@b.com 4111111111111111 pin=1234 tel 123-4567")
+	log.Debug("filtered")
+	assert.Equal(t, []string{"# # pin=# tel #"}, memoryMsgs(mw.Snapshot()))
+}
+
+func TestRedactConfigINI(t *testing.T) {
+	testRedactConfig(t, "testdata/log-redact.ini")
+}
+
+func TestRedactConfigJSON(t *testing.T) {
+	testRedactConfig(t, "testdata/log-redact.json")
+}
@@ -0,0 +1,165 @@
+package log
+
+import (
+	"fmt"
+)
+
+// Route a route of the RouteWriter
+type Route struct {
+	Filter Filter // the route condition, nil: match all events
+	Writer Writer // the route writer
+}
+
+// Match check the event matches the route condition
+func (r *Route) Match(le *Event) bool {
+	return r.Filter == nil || !r.Filter.Reject(le)
+}
+
+// RouteWriter implements Writer.
+// It dispatches the event to the writer of the first matched route.
+// The route condition is a filter configuration string, example: "logger:sql.* levels:trace-debug".
+// A route without condition matches all events (it should be the last route).
+type RouteWriter struct {
+	Routes []*Route
+	Logfil Filter // log filter
+
+	wstat writerStat // the writer statistics
+}
+
+// NewRouteWriter create a route writer
+func NewRouteWriter(rs ...*Route) *RouteWriter {
+	return &RouteWriter{Routes: rs}
+}
+
+// AddRoute add a route with the condition 'cond' ("": match all events) and the writer 'w'
+func (rw *RouteWriter) AddRoute(cond string, w Writer) error {
+	f, err := ParseLogFilter(cond)
+	if err != nil {
+		return fmt.Errorf("RouteWriter - Invalid route %q: %v", cond, err)
+	}
+
+	rw.Routes = append(rw.Routes, &Route{Filter: f, Writer: w})
+	return nil
+}
+
+// SetRoutes create the routes by the configuration items.
+// Each item is a writer configuration map with the route condition "route".
+// The created writers are closed if any item is invalid.
+func (rw *RouteWriter) SetRoutes(rs []interface{}) error {
+	nw := &RouteWriter{}
+	for _, i := range rs {
+		if err := nw.addRouteConfig(i); err != nil {
+			nw.Close()
+			return err
+		}
+	}
+
+	rw.Routes = nw.Routes
+	return nil
+}
+
+func (rw *RouteWriter) addRouteConfig(i interface{}) error {
+	c, ok := i.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("RouteWriter - Invalid route item: %v", i)
+	}
+
+	n, ok := c["_"]
+	if !ok {
+		return fmt.Errorf("RouteWriter - Missing writer type: %v", c)
+	}
+
+	cond := ""
+	if v, ok := c["route"]; ok && v != nil {
+		cond = fmt.Sprint(v)
+	}
+	f, err := ParseLogFilter(cond)
+	if err != nil {
+		return fmt.Errorf("RouteWriter - Invalid route %q: %v", cond, err)
+	}
+
+	w := CreateWriter(fmt.Sprint(n))
+	if w == nil {
+		return fmt.Errorf("RouteWriter - Invalid writer name: %v", n)
+	}
+
+	wc := make(map[string]interface{}, len(c))
+	for k, v := range c {
+		if k != "route" {
+			wc[k] = v
+		}
+	}
+	if err := ConfigWriter(w, wc); err != nil {
+		w.Close()
+		return err
+	}
+
+	rw.Routes = append(rw.Routes, &Route{Filter: f, Writer: w})
+	return nil
+}
+
+// SetFilter set the log filter
+func (rw *RouteWriter) SetFilter(filter string) error {
+	f, err := ParseLogFilter(filter)
+	if err != nil {
+		return fmt.Errorf("RouteWriter - %v", err)
+	}
+	rw.Logfil = f
+	return nil
+}
+
+// stats return the statistics of the writer
+func (rw *RouteWriter) stats() *writerStat {
+	return &rw.wstat
+}
+
+// Write write the event to the writer of the first matched route
+func (rw *RouteWriter) Write(le *Event) {
+	if reject(&rw.wstat, rw.Logfil, le) {
+		return
+	}
+
+	for _, r := range rw.Routes {
+		if r.Match(le) {
+			r.Writer.Write(le)
+			return
+		}
+	}
+}
+
+// Flush flush the writers of all routes, a writer shared by several routes is flushed once
+func (rw *RouteWriter) Flush() {
+	for _, w := range rw.writers() {
+		w.Flush()
+	}
+}
+
+// Close close the writers of all routes, a writer shared by several routes is closed once
+func (rw *RouteWriter) Close() {
+	for _, w := range rw.writers() {
+		w.Close()
+	}
+}
+
+// writers return the distinct writers of the routes
+func (rw *RouteWriter) writers() []Writer {
+	ws := make([]Writer, 0, len(rw.Routes))
+	wm := make(map[Writer]bool, len(rw.Routes))
+	for _, r := range rw.Routes {
+		w := r.Writer
+		if isComparable(w) {
+			if wm[w] {
+				continue
+			}
+			wm[w] = true
+		}
+		ws = append(ws, w)
+	}
+	return ws
+}
+
+func init() {
+	RegisterWriter("route", func() Writer {
+		return &RouteWriter{}
+	})
+}
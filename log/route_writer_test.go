@@ -0,0 +1,148 @@
+package log
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteWriter(t *testing.T) {
+	sw, ew, ow := &testBatchWriter{}, &testBatchWriter{}, &testBatchWriter{}
+
+	rw := NewRouteWriter()
+	rw.AddRoute("logger:sql*", sw)
+	rw.AddRoute("levels:fatal-error", ew)
+	rw.AddRoute("", ow)
+
+	log := NewLog()
+	log.SetWriter(rw)
+	log.GetLogger("sql").Error("sql error")
+	log.GetLogger("sql.tx").Debug("sql debug")
+	log.GetLogger("web").Error("web error")
+	log.GetLogger("web").Info("web info")
+	log.Fatal("fatal")
+	log.Close()
+
+	assert.Equal(t, []string{"sql error", "sql debug"}, sw.msgs)
+	assert.Equal(t, []string{"web error", "fatal"}, ew.msgs)
+	assert.Equal(t, []string{"web info"}, ow.msgs)
+	assert.True(t, sw.closed && ew.closed && ow.closed)
+}
+
+func TestRouteWriterFilter(t *testing.T) {
+	ew := &testBatchWriter{}
+
+	rw := NewRouteWriter(&Route{Filter: NewLogFilter("levels:error"), Writer: ew})
+	rw.SetFilter("logger:web")
+
+	log := NewLog()
+	log.SetWriter(rw)
+	log.GetLogger("web").Error("web error")
+	log.GetLogger("web").Warn("web warn")
+	log.GetLogger("sql").Error("sql error")
+
+	assert.Equal(t, []string{"web error"}, ew.msgs)
+}
+
+func testRouteConfig(t *testing.T, file string) {
+	log := NewLog()
+	if !assert.Nil(t, log.Config(file)) {
+		return
+	}
+
+	rw, ok := log.GetWriter().(*RouteWriter)
+	if !assert.True(t, ok) || !assert.Equal(t, 3, len(rw.Routes)) {
+		return
+	}
+
+	log.GetLogger("sql").Error("sql error")
+	log.GetLogger("web").Error("web error")
+	log.GetLogger("web").Info("web info")
+	log.GetLogger("web").Debug("web debug")
+
+	mws := make([]*MemoryWriter, 3)
+	for i, r := range rw.Routes {
+		mws[i] = r.Writer.(*MemoryWriter)
+		assert.Equal(t, 10, mws[i].Size)
+	}
+	assert.Nil(t, rw.Routes[2].Filter)
+
+	assert.Equal(t, []string{"sql error"}, memoryMsgs(mws[0].Snapshot()))
+	assert.Equal(t, []string{"web error"}, memoryMsgs(mws[1].Snapshot()))
+	assert.Equal(t, []string{"web info"}, memoryMsgs(mws[2].Snapshot()))
+}
+
+func TestRouteWriterConfigINI(t *testing.T) {
+	testRouteConfig(t, "testdata/log-route.ini")
+}
+
+func TestRouteWriterConfigJSON(t *testing.T) {
+	testRouteConfig(t, "testdata/log-route.json")
+}
+
+func TestRouteWriterConfigInvalid(t *testing.T) {
+	rw := &RouteWriter{}
+	assert.NotNil(t, rw.SetRoutes([]interface{}{"x"}))
+	assert.NotNil(t, rw.SetRoutes([]interface{}{map[string]interface{}{"route": "levels:error"}}))
+	assert.NotNil(t, rw.SetRoutes([]interface{}{map[string]interface{}{"_": "xxx"}}))
+	assert.NotNil(t, rw.SetRoutes([]interface{}{map[string]interface{}{"_": "memory", "route": "levels:xxx"}}))
+	assert.NotNil(t, rw.SetRoutes([]interface{}{map[string]interface{}{"_": "memory", "route": "xxx"}}))
+	assert.Equal(t, 0, len(rw.Routes))
+
+	assert.NotNil(t, rw.AddRoute("levels:error-xxx", &testBatchWriter{}))
+	assert.Nil(t, rw.AddRoute("", &testBatchWriter{}))
+	assert.Equal(t, 1, len(rw.Routes))
+}
+
+// countWriter counts the flush and close calls
+type countWriter struct {
+	flushed int
+	closed  int
+}
+
+func (cw *countWriter) Write(le *Event) {
+}
+
+func (cw *countWriter) Flush() {
+	cw.flushed++
+}
+
+func (cw *countWriter) Close() {
+	cw.closed++
+}
+
+func TestRouteWriterSharedWriter(t *testing.T) {
+	cw, ow := &countWriter{}, &countWriter{}
+
+	rw := NewRouteWriter()
+	rw.AddRoute("logger:sql*", cw)
+	rw.AddRoute("levels:fatal-error", cw)
+	rw.AddRoute("", ow)
+
+	rw.Flush()
+	rw.Close()
+
+	assert.Equal(t, 1, cw.flushed)
+	assert.Equal(t, 1, cw.closed)
+	assert.Equal(t, 1, ow.flushed)
+	assert.Equal(t, 1, ow.closed)
+}
+
+func TestRouteWriterConfigCircular(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logroute")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "log.ini")
+	ioutil.WriteFile(file, []byte("writer = a\n[writer.a]\n_ = route\nroutes = b\n[writer.b]\n_ = route\nroutes = a\n"), 0666)
+
+	err = NewLog().Config(file)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "Circular")
+	}
+}
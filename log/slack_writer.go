@@ -2,7 +2,6 @@ package log
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/pandafw/pango/net/slack"
@@ -17,6 +16,8 @@ type SlackWriter struct {
 	Subfmt   Formatter // subject formatter
 	Logfmt   Formatter // log formatter
 	Logfil   Filter    // log filter
+
+	wstat writerStat // the writer statistics
 }
 
 // SetSubject set the subject formatter
@@ -30,8 +31,13 @@ func (sw *SlackWriter) SetFormat(format string) {
 }
 
 // SetFilter set the log filter
-func (sw *SlackWriter) SetFilter(filter string) {
-	sw.Logfil = NewLogFilter(filter)
+func (sw *SlackWriter) SetFilter(filter string) error {
+	f, err := ParseLogFilter(filter)
+	if err != nil {
+		return fmt.Errorf("SlackWriter - %v", err)
+	}
+	sw.Logfil = f
+	return nil
 }
 
 // SetTimeout set timeout
@@ -44,9 +50,14 @@ func (sw *SlackWriter) SetTimeout(timeout string) error {
 	return nil
 }
 
+// stats return the statistics of the writer
+func (sw *SlackWriter) stats() *writerStat {
+	return &sw.wstat
+}
+
 // Write send log message to slack
 func (sw *SlackWriter) Write(le *Event) {
-	if sw.Logfil != nil && sw.Logfil.Reject(le) {
+	if reject(&sw.wstat, sw.Logfil, le) {
 		return
 	}
 	if sw.Subfmt == nil {
@@ -72,7 +83,7 @@ func (sw *SlackWriter) Write(le *Event) {
 
 	err := slack.Post(sw.Webhook, sw.Timeout, sm)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "SlackWriter(%q) - Post(): %v\n", sw.Webhook, err)
+		writerErrorf(sw, "SlackWriter(%q) - Post(): %v\n", sw.Webhook, err)
 	}
 }
 
@@ -3,7 +3,6 @@ package log
 import (
 	"crypto/tls"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/pandafw/pango/net/email"
@@ -24,6 +23,7 @@ type SMTPWriter struct {
 	Logfmt   Formatter // log formatter
 	Logfil   Filter    // log filter
 
+	wstat  writerStat        // the writer statistics
 	email  *email.Email      // email
 	sender *email.SMTPSender // email sender
 }
@@ -39,8 +39,13 @@ func (sw *SMTPWriter) SetFormat(format string) {
 }
 
 // SetFilter set the log filter
-func (sw *SMTPWriter) SetFilter(filter string) {
-	sw.Logfil = NewLogFilter(filter)
+func (sw *SMTPWriter) SetFilter(filter string) error {
+	f, err := ParseLogFilter(filter)
+	if err != nil {
+		return fmt.Errorf("SMTPWriter - %v", err)
+	}
+	sw.Logfil = f
+	return nil
 }
 
 // SetTo set To recipients
@@ -63,9 +68,14 @@ func (sw *SMTPWriter) SetTimeout(timeout string) error {
 	return nil
 }
 
+// stats return the statistics of the writer
+func (sw *SMTPWriter) stats() *writerStat {
+	return &sw.wstat
+}
+
 // Write send log message to smtp server.
 func (sw *SMTPWriter) Write(le *Event) {
-	if sw.Logfil != nil && sw.Logfil.Reject(le) {
+	if reject(&sw.wstat, sw.Logfil, le) {
 		return
 	}
 
@@ -85,20 +95,20 @@ func (sw *SMTPWriter) Write(le *Event) {
 		m := &email.Email{}
 		err := m.SetFrom(sw.From)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "SMTPWriter(%s:%d) - SetFrom(): %v\n", sw.Host, sw.Port, err)
+			writerErrorf(sw, "SMTPWriter(%s:%d) - SetFrom(): %v\n", sw.Host, sw.Port, err)
 			return
 		}
 		for _, a := range sw.Tos {
 			err := m.AddTo(a)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "SMTPWriter(%s:%d) - AddTo(): %v\n", sw.Host, sw.Port, err)
+				writerErrorf(sw, "SMTPWriter(%s:%d) - AddTo(): %v\n", sw.Host, sw.Port, err)
 				return
 			}
 		}
 		for _, a := range sw.Ccs {
 			err := m.AddCc(a)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "SMTPWriter(%s:%d) - AddCc(): %v\n", sw.Host, sw.Port, err)
+				writerErrorf(sw, "SMTPWriter(%s:%d) - AddCc(): %v\n", sw.Host, sw.Port, err)
 				return
 			}
 		}
@@ -118,7 +128,7 @@ func (sw *SMTPWriter) Write(le *Event) {
 	if !sw.sender.IsDialed() {
 		err := sw.sender.Dial()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "SMTPWriter(%s:%d) - Dial(): %v\n", sw.Host, sw.Port, err)
+			writerErrorf(sw, "SMTPWriter(%s:%d) - Dial(): %v\n", sw.Host, sw.Port, err)
 			return
 		}
 	}
@@ -128,7 +138,7 @@ func (sw *SMTPWriter) Write(le *Event) {
 
 	err := sw.sender.Send(sw.email)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "SMTPWriter(%s:%d) - Send(): %v\n", sw.Host, sw.Port, err)
+		writerErrorf(sw, "SMTPWriter(%s:%d) - Send(): %v\n", sw.Host, sw.Port, err)
 	}
 }
 
@@ -141,7 +151,7 @@ func (sw *SMTPWriter) Close() {
 	if sw.sender != nil {
 		err := sw.sender.Close()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "SMTPWriter(%s:%d) - Close(): %v\n", sw.Host, sw.Port, err)
+			writerErrorf(sw, "SMTPWriter(%s:%d) - Close(): %v\n", sw.Host, sw.Port, err)
 		}
 		sw.sender = nil
 	}
@@ -2,19 +2,37 @@ package log
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"os"
+	"reflect"
 
 	"github.com/pandafw/pango/iox"
 )
 
+// isColorTerminal check the output supports color (replaceable for test)
+var isColorTerminal = iox.IsColorTerminal
+
 // StreamWriter implements log Writer Interface and writes messages to terminal.
+// If Color is true, the messages are colored only when the output is a terminal
+// and the environment variable NO_COLOR is not set.
+// If the formatter contains color directives (%K{color}), only the specified tokens are colored,
+// otherwise the whole message is colored by the log level.
+// If Split is true, the ERROR+ messages are written to ErrOutput (default: os.Stderr).
 type StreamWriter struct {
-	Color  bool         //this filed is useful only when system's terminal supports color
-	Output io.Writer    // log output
-	Logfmt Formatter    // log formatter
-	Logfil Filter       // log filter
-	bb     bytes.Buffer // log buffer
+	Color     bool         //this filed is useful only when system's terminal supports color
+	Split     bool         // write the ERROR+ messages to ErrOutput
+	Output    io.Writer    // log output
+	ErrOutput io.Writer    // log output for the ERROR+ messages in split mode, default: os.Stderr
+	Logfmt    Formatter    // log formatter
+	Logfil    Filter       // log filter
+	bb        bytes.Buffer // log buffer
+
+	wstat  writerStat // the writer statistics
+	dout   io.Writer  // the detected Output
+	derr   io.Writer  // the detected ErrOutput
+	ocolor bool       // Output supports color
+	ecolor bool       // ErrOutput supports color
 }
 
 // SetFormat set the log formatter
@@ -23,19 +41,44 @@ func (sw *StreamWriter) SetFormat(format string) {
 }
 
 // SetFilter set the log filter
-func (sw *StreamWriter) SetFilter(filter string) {
-	sw.Logfil = NewLogFilter(filter)
+func (sw *StreamWriter) SetFilter(filter string) error {
+	f, err := ParseLogFilter(filter)
+	if err != nil {
+		return fmt.Errorf("StreamWriter - %v", err)
+	}
+	sw.Logfil = f
+	return nil
+}
+
+// stats return the statistics of the writer
+func (sw *StreamWriter) stats() *writerStat {
+	return &sw.wstat
 }
 
 // Write write message in console.
 func (sw *StreamWriter) Write(le *Event) {
-	if sw.Logfil != nil && sw.Logfil.Reject(le) {
+	if reject(&sw.wstat, sw.Logfil, le) {
 		return
 	}
 
 	if sw.Output == nil {
 		sw.Output = os.Stdout
 	}
+	if sw.Split && sw.ErrOutput == nil {
+		sw.ErrOutput = os.Stderr
+	}
+	if !sameWriter(sw.dout, sw.Output) {
+		sw.dout, sw.ocolor = sw.Output, isColorTerminal(sw.Output)
+	}
+	if sw.ErrOutput != nil && !sameWriter(sw.derr, sw.ErrOutput) {
+		sw.derr, sw.ecolor = sw.ErrOutput, isColorTerminal(sw.ErrOutput)
+	}
+
+	out, color := sw.Output, sw.ocolor
+	if sw.Split && le.Level <= LevelError {
+		out, color = sw.ErrOutput, sw.ecolor
+	}
+	color = color && sw.Color
 
 	lf := sw.Logfmt
 	if lf == nil {
@@ -46,13 +89,20 @@ func (sw *StreamWriter) Write(le *Event) {
 	}
 
 	sw.bb.Reset()
-	lf.Write(&sw.bb, le)
-	if sw.Color {
-		sw.Output.Write([]byte(colors[le.Level]))
-		sw.Output.Write(sw.bb.Bytes())
-		sw.Output.Write([]byte(colors[0]))
+	if color {
+		if cf, ok := lf.(ColorFormatter); ok && cf.IsColored() {
+			cf.WriteColor(&sw.bb, le)
+			out.Write(sw.bb.Bytes())
+			return
+		}
+
+		lf.Write(&sw.bb, le)
+		out.Write([]byte(colors[le.Level]))
+		out.Write(sw.bb.Bytes())
+		out.Write([]byte(colors[0]))
 	} else {
-		sw.Output.Write(sw.bb.Bytes())
+		lf.Write(&sw.bb, le)
+		out.Write(sw.bb.Bytes())
 	}
 }
 
@@ -64,6 +114,16 @@ func (sw *StreamWriter) Flush() {
 func (sw *StreamWriter) Close() {
 }
 
+// sameWriter check the writers 'a' and 'b' are the same comparable writer
+func sameWriter(a, b io.Writer) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	ta := reflect.TypeOf(a)
+	return ta == reflect.TypeOf(b) && ta.Comparable() && a == b
+}
+
 var colors = []string{
 	iox.ConsoleColor.Reset,   // None
 	iox.ConsoleColor.Red,     // Fatal
@@ -1,9 +1,14 @@
 package log
 
 import (
+	"bytes"
+	"io"
 	"strconv"
 	"sync"
 	"testing"
+
+	"github.com/pandafw/pango/iox"
+	"github.com/stretchr/testify/assert"
 )
 
 func testConsoleCalls(log Logger, loop int) {
@@ -96,3 +101,114 @@ func TestConsoleStackTrace(t *testing.T) {
 	testConsoleCalls(log, 1)
 	log.Close()
 }
+
+func testColorTerminal(t *testing.T, color bool) {
+	ict := isColorTerminal
+	isColorTerminal = func(w io.Writer) bool {
+		return color
+	}
+	t.Cleanup(func() {
+		isColorTerminal = ict
+	})
+}
+
+// Test console without terminal
+func TestConsoleNoTerminal(t *testing.T) {
+	testColorTerminal(t, false)
+
+	bb := &bytes.Buffer{}
+	log := NewLog()
+	log.SetWriter(&StreamWriter{Color: true, Output: bb})
+	log.SetFormatter(NewTextFormatter("%K{level}%l%K - %m%n"))
+	log.Info("info")
+	log.Close()
+
+	assert.Equal(t, "INFO - info"+eol, bb.String())
+}
+
+// Test console whole line color
+func TestConsoleColorLine(t *testing.T) {
+	testColorTerminal(t, true)
+
+	bb := &bytes.Buffer{}
+	log := NewLog()
+	log.SetWriter(&StreamWriter{Color: true, Output: bb})
+	log.SetFormatter(NewTextFormatter("%l - %m%n"))
+	log.Warn("warn")
+	log.Close()
+
+	assert.Equal(t, iox.ConsoleColor.Yellow+"WARN - warn"+eol+iox.ConsoleColor.Reset, bb.String())
+}
+
+// Test console color directives
+func TestConsoleColorToken(t *testing.T) {
+	testColorTerminal(t, true)
+
+	bb := &bytes.Buffer{}
+	log := NewLog()
+	log.SetWriter(&StreamWriter{Color: true, Output: bb})
+	log.SetFormatter(NewTextFormatter("%K{level}%l%K %K{cyan}%c%K{reset} - %m%n"))
+	log.GetLogger("web").Error("error")
+	log.Close()
+
+	assert.Equal(t, iox.ConsoleColor.Magenta+"ERROR"+iox.ConsoleColor.Reset+" "+iox.ConsoleColor.Cyan+"web"+iox.ConsoleColor.Reset+" - error"+eol, bb.String())
+}
+
+// Test console split
+func TestConsoleSplit(t *testing.T) {
+	testColorTerminal(t, false)
+
+	ob, eb := &bytes.Buffer{}, &bytes.Buffer{}
+	log := NewLog()
+	log.SetWriter(&StreamWriter{Color: true, Split: true, Output: ob, ErrOutput: eb})
+	log.SetFormatter(NewTextFormatter("%l %m%n"))
+	log.SetLevel(LevelTrace)
+	testConsoleCalls(log, 1)
+	log.Close()
+
+	assert.Equal(t, "WARN warn <0>"+eol+"INFO info <0>"+eol+"DEBUG debug<0>"+eol+"TRACE trace<0>"+eol+
+		"WARN warn (0)"+eol+"INFO info (0)"+eol+"DEBUG debug(0)"+eol+"TRACE trace(0)"+eol, ob.String())
+	assert.Equal(t, "FATAL fatal<0>"+eol+"ERROR error<0>"+eol+"FATAL fatal(0)"+eol+"ERROR error(0)"+eol, eb.String())
+}
+
+// Test console config
+func TestConsoleConfig(t *testing.T) {
+	sw := CreateWriter("console").(*StreamWriter)
+	err := ConfigWriter(sw, map[string]interface{}{
+		"color":  "false",
+		"split":  "true",
+		"format": "%K{level}%l%K %m",
+	})
+
+	assert.Nil(t, err)
+	assert.False(t, sw.Color)
+	assert.True(t, sw.Split)
+	if tf, ok := sw.Logfmt.(*TextFormatter); assert.True(t, ok) {
+		assert.True(t, tf.IsColored())
+	}
+}
+
+// Test console color detection after the output is changed
+func TestConsoleOutputChanged(t *testing.T) {
+	ob, cb := &bytes.Buffer{}, &bytes.Buffer{}
+
+	ict := isColorTerminal
+	isColorTerminal = func(w io.Writer) bool {
+		return w == cb
+	}
+	defer func() {
+		isColorTerminal = ict
+	}()
+
+	sw := &StreamWriter{Color: true, Output: ob, Logfmt: NewTextFormatter("%m")}
+	log := NewLog()
+	log.SetWriter(sw)
+	log.Warn("plain")
+
+	sw.Output = cb
+	log.Warn("color")
+	log.Close()
+
+	assert.Equal(t, "plain", ob.String())
+	assert.Equal(t, iox.ConsoleColor.Yellow+"color"+iox.ConsoleColor.Reset, cb.String())
+}
@@ -0,0 +1,313 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Syslog facilities
+// SyslogFacilityKern the SyslogWriter.Facility value of the kern (0) facility,
+// because the zero value of the Facility means user (1)
+const SyslogFacilityKern = -1
+
+var syslogFacilities = map[string]int{
+	"kern":     SyslogFacilityKern,
+	"user":     1,
+	"mail":     2,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"lpr":      6,
+	"news":     7,
+	"uucp":     8,
+	"cron":     9,
+	"authpriv": 10,
+	"ftp":      11,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+// default local syslog unix sockets
+var syslogLocalAddrs = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogWriter implements Writer.
+// It writes messages to the syslog server by RFC 5424 or RFC 3164 protocol.
+// Net: "udp", "tcp", "unix", "unixgram". If Net and Addr are empty, the local syslog unix socket is used.
+// The messages are framed by octet-counting (RFC 6587) for the stream connection (tcp, unix).
+type SyslogWriter struct {
+	Net      string        // network: "udp", "tcp", "unix", "unixgram"
+	Addr     string        // syslog server address
+	Protocol string        // syslog protocol: "rfc5424" (default), "rfc3164"
+	Facility int           // syslog facility, 0: user (1), SyslogFacilityKern: kern (0)
+	Hostname string        // hostname, default: os.Hostname()
+	AppName  string        // app name, default: the executable file name
+	Timeout  time.Duration // dial timeout
+	Logfmt   Formatter     // log formatter
+	Logfil   Filter        // log filter
+
+	wstat  writerStat // the writer statistics
+	conn   net.Conn
+	stream bool
+	bm     bytes.Buffer
+	bb     bytes.Buffer
+}
+
+// SetFormat set the log formatter
+func (sw *SyslogWriter) SetFormat(format string) {
+	sw.Logfmt = NewLogFormatter(format)
+}
+
+// SetFilter set the log filter
+func (sw *SyslogWriter) SetFilter(filter string) error {
+	f, err := ParseLogFilter(filter)
+	if err != nil {
+		return fmt.Errorf("SyslogWriter - %v", err)
+	}
+	sw.Logfil = f
+	return nil
+}
+
+// SetTimeout set timeout
+func (sw *SyslogWriter) SetTimeout(timeout string) error {
+	tmo, err := time.ParseDuration(timeout)
+	if err != nil {
+		return fmt.Errorf("SyslogWriter - Invalid timeout: %v", err)
+	}
+	sw.Timeout = tmo
+	return nil
+}
+
+// SetFacility set the facility by name (user, daemon, local0, ...) or number
+func (sw *SyslogWriter) SetFacility(facility string) error {
+	if f, ok := syslogFacilities[strings.ToLower(facility)]; ok {
+		sw.Facility = f
+		return nil
+	}
+
+	f, err := strconv.Atoi(facility)
+	if err != nil || f < 0 || f > 23 {
+		return fmt.Errorf("SyslogWriter - Invalid facility: %v", facility)
+	}
+	if f == 0 {
+		f = SyslogFacilityKern
+	}
+	sw.Facility = f
+	return nil
+}
+
+// SetProtocol set the syslog protocol (rfc5424, rfc3164)
+func (sw *SyslogWriter) SetProtocol(protocol string) error {
+	switch strings.TrimPrefix(strings.ToLower(protocol), "rfc") {
+	case "", "5424":
+		sw.Protocol = "rfc5424"
+	case "3164":
+		sw.Protocol = "rfc3164"
+	default:
+		return fmt.Errorf("SyslogWriter - Invalid protocol: %v", protocol)
+	}
+	return nil
+}
+
+// stats return the statistics of the writer
+func (sw *SyslogWriter) stats() *writerStat {
+	return &sw.wstat
+}
+
+// Write write logger message to syslog server.
+func (sw *SyslogWriter) Write(le *Event) {
+	if reject(&sw.wstat, sw.Logfil, le) {
+		return
+	}
+
+	lf := sw.Logfmt
+	if lf == nil {
+		lf = le.Logger.GetFormatter()
+		if lf == nil {
+			lf = TextFmtDefault
+		}
+	}
+
+	sw.dial()
+	if sw.conn == nil {
+		return
+	}
+
+	// format msg
+	sw.bm.Reset()
+	lf.Write(&sw.bm, le)
+	sw.format(le)
+
+	// write log
+	_, err := sw.conn.Write(sw.bb.Bytes())
+	if err != nil {
+		// This is probably due to a timeout, so reconnect and try again.
+		sw.Close()
+		sw.dial()
+		if sw.conn == nil {
+			return
+		}
+		_, err := sw.conn.Write(sw.bb.Bytes())
+		if err != nil {
+			writerErrorf(sw, "SyslogWriter(%q) - Write(%s): %v\n", sw.Addr, sw.bb.Bytes(), err)
+			sw.Close()
+		}
+	}
+}
+
+// Flush implementing method. empty.
+func (sw *SyslogWriter) Flush() {
+}
+
+// Close close the connection.
+func (sw *SyslogWriter) Close() {
+	if sw.conn != nil {
+		err := sw.conn.Close()
+		if err != nil {
+			writerErrorf(sw, "SyslogWriter(%q) - Close(): %v\n", sw.Addr, err)
+		}
+		sw.conn = nil
+	}
+}
+
+// format format the syslog message (header + sw.bm) to sw.bb
+func (sw *SyslogWriter) format(le *Event) {
+	msg := bytes.TrimRight(sw.bm.Bytes(), "\r\n")
+
+	if sw.Hostname == "" {
+		sw.Hostname, _ = os.Hostname()
+		if sw.Hostname == "" {
+			sw.Hostname = "-"
+		}
+	}
+	if sw.AppName == "" {
+		sw.AppName = filepath.Base(os.Args[0])
+	}
+
+	facility := sw.Facility
+	switch {
+	case facility == 0:
+		facility = 1
+	case facility < 0:
+		facility = 0
+	}
+
+	pri := facility*8 + syslogSeverity(le.Level)
+
+	var hdr string
+	if sw.Protocol == "rfc3164" {
+		// <PRI>TIMESTAMP HOSTNAME TAG[PID]: MSG
+		// TAG: 1*32 alphanumeric characters
+		hdr = fmt.Sprintf("<%d>%s %s %s[%d]: ",
+			pri, le.When.Format(time.Stamp), syslogField(sw.Hostname, 255), syslogField(sw.AppName, 32), os.Getpid())
+	} else {
+		// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+		// HOSTNAME: 1*255PRINTUSASCII, APP-NAME: 1*48PRINTUSASCII, MSGID: 1*32PRINTUSASCII
+		msgid := ""
+		if le.Logger != nil {
+			msgid = le.Logger.GetName()
+		}
+		hdr = fmt.Sprintf("<%d>1 %s %s %s %d %s - ",
+			pri, le.When.Format("2006-01-02T15:04:05.000000Z07:00"),
+			syslogField(sw.Hostname, 255), syslogField(sw.AppName, 48), os.Getpid(), syslogField(msgid, 32))
+	}
+
+	sw.bb.Reset()
+	if sw.stream {
+		// octet-counting framing
+		sw.bb.WriteString(strconv.Itoa(len(hdr) + len(msg)))
+		sw.bb.WriteByte(' ')
+	}
+	sw.bb.WriteString(hdr)
+	sw.bb.Write(msg)
+}
+
+func (sw *SyslogWriter) dial() {
+	if sw.conn != nil {
+		return
+	}
+
+	if sw.Net == "" && sw.Addr == "" {
+		for _, a := range syslogLocalAddrs {
+			for _, n := range []string{"unixgram", "unix"} {
+				conn, err := net.DialTimeout(n, a, sw.Timeout)
+				if err == nil {
+					sw.conn = conn
+					sw.stream = (n == "unix")
+					return
+				}
+			}
+		}
+		writerErrorf(sw, "SyslogWriter - Dial(): local syslog server not found\n")
+		return
+	}
+
+	if sw.Net == "" {
+		sw.Net = "udp"
+	}
+
+	conn, err := net.DialTimeout(sw.Net, sw.Addr, sw.Timeout)
+	if err != nil {
+		writerErrorf(sw, "SyslogWriter(%q) - Dial(%q): %v\n", sw.Addr, sw.Net, err)
+		return
+	}
+
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		tcpConn.SetKeepAlive(true)
+	}
+
+	sw.conn = conn
+	sw.stream = (sw.Net != "udp" && sw.Net != "udp4" && sw.Net != "udp6" && sw.Net != "unixgram")
+}
+
+// syslogField replace the non PRINTUSASCII (33-126) characters of the header field 's' with '_',
+// truncate it to max 'n' characters, and return the NILVALUE "-" for the empty field
+func syslogField(s string, n int) string {
+	if s == "" {
+		return "-"
+	}
+
+	bs := []byte(s)
+	if len(bs) > n {
+		bs = bs[:n]
+	}
+	for i, b := range bs {
+		if b < 33 || b > 126 {
+			bs[i] = '_'
+		}
+	}
+	return string(bs)
+}
+
+// syslogSeverity map the log level to the syslog severity
+func syslogSeverity(lvl Level) int {
+	switch lvl {
+	case LevelFatal:
+		return 2 // critical
+	case LevelError:
+		return 3 // error
+	case LevelWarn:
+		return 4 // warning
+	case LevelInfo:
+		return 6 // informational
+	default:
+		return 7 // debug
+	}
+}
+
+func init() {
+	RegisterWriter("syslog", func() Writer {
+		return &SyslogWriter{Protocol: "rfc5424", Timeout: time.Second * 2}
+	})
+}
@@ -0,0 +1,149 @@
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyslogWriterUDP5424(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		skipTest(t, err.Error())
+		return
+	}
+	defer pc.Close()
+
+	log := NewLog()
+	log.SetFormatter(NewTextFormatter("%m%n"))
+	log.SetWriter(&SyslogWriter{Net: "udp", Addr: pc.LocalAddr().String(), Facility: 16, Hostname: "host", AppName: "app"})
+
+	lg := log.GetLogger("db")
+	lg.Error("hello syslog")
+	log.Close()
+
+	buf := make([]byte, 1024)
+	pc.SetReadDeadline(time.Now().Add(time.Second * 2))
+	n, _, err := pc.ReadFrom(buf)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	msg := string(buf[:n])
+	pre := "<131>1 "
+	suf := " host app " + strconv.Itoa(os.Getpid()) + " db - hello syslog"
+	assert.Equal(t, pre, msg[:len(pre)])
+	assert.Equal(t, suf, msg[len(msg)-len(suf):])
+
+	_, err = time.Parse("2006-01-02T15:04:05.000000Z07:00", msg[len(pre):len(msg)-len(suf)])
+	assert.Nil(t, err, msg)
+}
+
+func TestSyslogWriterTCP3164(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		skipTest(t, err.Error())
+		return
+	}
+	defer ln.Close()
+
+	revChan := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// octet-counting
+		br := bufio.NewReader(conn)
+		for {
+			var n int
+			if _, err := fmt.Fscanf(br, "%d ", &n); err != nil {
+				close(revChan)
+				return
+			}
+			bs := make([]byte, n)
+			if _, err := io.ReadFull(br, bs); err != nil {
+				close(revChan)
+				return
+			}
+			revChan <- string(bs)
+		}
+	}()
+
+	sw := CreateWriter("syslog")
+	assert.Nil(t, ConfigWriter(sw, map[string]interface{}{
+		"net":      "tcp",
+		"addr":     ln.Addr().String(),
+		"protocol": "rfc3164",
+		"facility": "daemon",
+		"hostname": "host",
+		"appName":  "app",
+		"format":   "%l - %m%n",
+	}))
+
+	log := NewLog()
+	log.SetWriter(sw)
+	log.Warn("hello")
+	log.Info("world")
+	log.Close()
+
+	suf := " host app[" + strconv.Itoa(os.Getpid()) + "]: "
+	for _, s := range []string{"<28>", "<30>"} {
+		msg := <-revChan
+		assert.Equal(t, s, msg[:4])
+		assert.Contains(t, msg, suf)
+	}
+}
+
+func TestSyslogWriterConfig(t *testing.T) {
+	sw := &SyslogWriter{}
+	assert.Nil(t, sw.SetFacility("local7"))
+	assert.Equal(t, 23, sw.Facility)
+	assert.Nil(t, sw.SetFacility("3"))
+	assert.Equal(t, 3, sw.Facility)
+	assert.Nil(t, sw.SetFacility("kern"))
+	assert.Equal(t, SyslogFacilityKern, sw.Facility)
+	assert.Nil(t, sw.SetFacility("0"))
+	assert.Equal(t, SyslogFacilityKern, sw.Facility)
+	assert.NotNil(t, sw.SetFacility("unknown"))
+
+	assert.Nil(t, sw.SetProtocol("RFC3164"))
+	assert.Equal(t, "rfc3164", sw.Protocol)
+	assert.NotNil(t, sw.SetProtocol("rfc1234"))
+}
+
+func TestSyslogWriterFormat(t *testing.T) {
+	sw := CreateWriter("syslog").(*SyslogWriter)
+	sw.Hostname = "host"
+	sw.AppName = "my app"
+
+	log := NewLog()
+	le := newEvent(log.GetLogger("db query\u00e9"), LevelError, "msg")
+	sw.bm.WriteString("msg")
+	sw.format(le)
+
+	pre := "<11>1 "
+	suf := " host my_app " + strconv.Itoa(os.Getpid()) + " db_query__ - msg"
+	msg := sw.bb.String()
+	assert.Equal(t, pre, msg[:len(pre)])
+	assert.Equal(t, suf, msg[len(msg)-len(suf):])
+
+	assert.Nil(t, sw.SetFacility("kern"))
+	sw.format(le)
+	assert.Equal(t, "<3>1 ", sw.bb.String()[:5])
+
+	// the zero value of the facility means user
+	zw := &SyslogWriter{Hostname: "host", AppName: "app"}
+	zw.bm.WriteString("msg")
+	zw.format(le)
+	assert.Equal(t, "<11>1 ", zw.bb.String()[:6])
+}
@@ -6,7 +6,6 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
-	"os"
 	"time"
 )
 
@@ -19,8 +18,9 @@ type WebhookWriter struct {
 	Logfmt      Formatter // log formatter
 	Logfil      Filter    // log filter
 
-	hc *http.Client
-	bb bytes.Buffer
+	wstat writerStat // the writer statistics
+	hc    *http.Client
+	bb    bytes.Buffer
 }
 
 // SetFormat set the log formatter
@@ -29,8 +29,13 @@ func (ew *WebhookWriter) SetFormat(format string) {
 }
 
 // SetFilter set the log filter
-func (ew *WebhookWriter) SetFilter(filter string) {
-	ew.Logfil = NewLogFilter(filter)
+func (ew *WebhookWriter) SetFilter(filter string) error {
+	f, err := ParseLogFilter(filter)
+	if err != nil {
+		return fmt.Errorf("WebhookWriter - %v", err)
+	}
+	ew.Logfil = f
+	return nil
 }
 
 // SetTimeout set timeout
@@ -43,12 +48,51 @@ func (ew *WebhookWriter) SetTimeout(timeout string) error {
 	return nil
 }
 
+// stats return the statistics of the writer
+func (ew *WebhookWriter) stats() *writerStat {
+	return &ew.wstat
+}
+
 // Write send log message to webhook
 func (ew *WebhookWriter) Write(le *Event) {
-	if ew.Logfil != nil && ew.Logfil.Reject(le) {
+	if reject(&ew.wstat, ew.Logfil, le) {
 		return
 	}
 
+	// format msg
+	ew.bb.Reset()
+	ew.format(le)
+
+	ew.send()
+}
+
+// WriteBatch send the log messages as a json array to webhook
+func (ew *WebhookWriter) WriteBatch(les []*Event) {
+	ew.bb.Reset()
+	ew.bb.WriteByte('[')
+
+	n := 0
+	for _, le := range les {
+		if reject(&ew.wstat, ew.Logfil, le) {
+			continue
+		}
+
+		if n > 0 {
+			ew.bb.WriteByte(',')
+		}
+		ew.format(le)
+		n++
+	}
+
+	if n == 0 {
+		return
+	}
+
+	ew.bb.WriteByte(']')
+	ew.send()
+}
+
+func (ew *WebhookWriter) format(le *Event) {
 	lf := ew.Logfmt
 	if lf == nil {
 		lf = le.Logger.GetFormatter()
@@ -57,6 +101,10 @@ func (ew *WebhookWriter) Write(le *Event) {
 		}
 	}
 
+	lf.Write(&ew.bb, le)
+}
+
+func (ew *WebhookWriter) send() {
 	if ew.hc == nil {
 		ew.hc = &http.Client{Timeout: ew.Timeout}
 	}
@@ -65,13 +113,9 @@ func (ew *WebhookWriter) Write(le *Event) {
 		ew.Method = "POST"
 	}
 
-	// format msg
-	ew.bb.Reset()
-	lf.Write(&ew.bb, le)
-
 	req, err := http.NewRequest(ew.Method, ew.Webhook, &ew.bb)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "WebhookWriter(%q) - NewRequest(%v): %v\n", ew.Webhook, ew.Method, err)
+		writerErrorf(ew, "WebhookWriter(%q) - NewRequest(%v): %v\n", ew.Webhook, ew.Method, err)
 		return
 	}
 	if len(ew.ContentType) > 0 {
@@ -80,14 +124,14 @@ func (ew *WebhookWriter) Write(le *Event) {
 
 	res, err := ew.hc.Do(req)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "WebhookWriter(%q) - Send(): %v\n", ew.Webhook, err)
+		writerErrorf(ew, "WebhookWriter(%q) - Send(): %v\n", ew.Webhook, err)
 		return
 	}
 	io.Copy(ioutil.Discard, res.Body)
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
-		fmt.Fprintf(os.Stderr, "WebhookWriter(%q) - Status: %s\n", ew.Webhook, res.Status)
+		writerErrorf(ew, "WebhookWriter(%q) - Status: %s\n", ew.Webhook, res.Status)
 	}
 }
 
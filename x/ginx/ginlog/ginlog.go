@@ -14,11 +14,15 @@ import (
 	"github.com/pandafw/pango/iox"
 	"github.com/pandafw/pango/log"
 	"github.com/pandafw/pango/net/httpx"
+	"github.com/pandafw/pango/str"
 )
 
 // DefaultTimeFormat default log time format
 const DefaultTimeFormat = "2006-01-02T15:04:05.000"
 
+// DefaultRequestIDHeader default request ID header name
+const DefaultRequestIDHeader = "X-Request-ID"
+
 // DefaultTextLogFormat default log format
 // TIME STATUS LATENCY LENGTH CLIENT_IP REMOTE_ADDR LISTEN METHOD HOST URL
 const DefaultTextLogFormat = "text:%t\t%S\t%T\t%L\t%c\t%r\t%A\t%m\t%h\t%u%n"
@@ -28,15 +32,17 @@ const DefaultJSONLogFormat = `json:{"when": %t, "status": %S, "latency": %T, "le
 
 // Logger access loger for GIN
 type Logger struct {
-	outputer io.Writer
-	formats  []fmtfunc
-	disabled bool
+	outputer  io.Writer
+	formats   []fmtfunc
+	disabled  bool
+	ridHeader string
 }
 
 type param struct {
-	Start time.Time
-	End   time.Time
-	Ctx   *gin.Context
+	Start     time.Time
+	End       time.Time
+	Ctx       *gin.Context
+	RequestID string
 }
 
 type fmtfunc func(p *param) string
@@ -64,6 +70,7 @@ func Default() *Logger {
 //   %S - HTTP status code of the response
 //   %L - Response body length
 //   %H{name} - Response header
+//   %i - Request ID (see SetRequestID)
 //   %n: EOL(Windows: "\r\n", Other: "\n")
 func New(outputer io.Writer, format string) *Logger {
 	return &Logger{outputer: outputer, formats: parseFormat(format)}
@@ -74,6 +81,15 @@ func (log *Logger) Disable(disabled bool) {
 	log.disabled = disabled
 }
 
+// SetRequestID set the request header name of the request ID and enable the request ID.
+// The request ID (a random string if the request header is empty) is set to the response header,
+// and is stored in the request context as the log field "requestID",
+// so that the application logs written by log.XxxCtx(c.Request.Context(), ...) can be correlated with the access log.
+// An empty header name disables the request ID.
+func (log *Logger) SetRequestID(header string) {
+	log.ridHeader = header
+}
+
 // Handler returns the gin.HandlerFunc
 func (log *Logger) Handler() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -90,6 +106,9 @@ func (log *Logger) handle(c *gin.Context) {
 	}
 
 	p := &param{Start: time.Now(), Ctx: c}
+	if log.ridHeader != "" {
+		p.RequestID = seedRequestID(c, log.ridHeader)
+	}
 
 	// process request
 	c.Next()
@@ -118,6 +137,19 @@ func (log *Logger) SetFormat(format string) {
 	log.formats = parseFormat(format)
 }
 
+// seedRequestID get (or generate) the request ID and store it in the request context
+func seedRequestID(c *gin.Context, header string) string {
+	rid := c.Request.Header.Get(header)
+	if rid == "" {
+		rid = str.RandLetterNumbers(16)
+	}
+	c.Header(header, rid)
+
+	ctx := log.ContextWithRequestID(c.Request.Context(), rid)
+	c.Request = c.Request.WithContext(ctx)
+	return rid
+}
+
 func parseFormat(format string) []fmtfunc {
 	if strings.HasPrefix(format, "text:") {
 		return parseTextFormat(format[5:])
@@ -189,6 +221,8 @@ func parseTextFormat(format string) []fmtfunc {
 			if p != "" {
 				fmt = responseHeader(p)
 			}
+		case 'i':
+			fmt = requestID
 		case 'n':
 			fmt = eolfmt
 		}
@@ -269,6 +303,8 @@ func parseJSONFormat(format string) []fmtfunc {
 			if p != "" {
 				fmt = quotefmtc(responseHeader(p))
 			}
+		case 'i':
+			fmt = quotefmtc(requestID)
 		case 'n':
 			fmt = eolfmt
 		}
@@ -372,6 +408,10 @@ func requestHeader(name string) fmtfunc {
 	}
 }
 
+func requestID(p *param) string {
+	return p.RequestID
+}
+
 func statusCode(p *param) string {
 	return strconv.Itoa(p.Ctx.Writer.Status())
 }
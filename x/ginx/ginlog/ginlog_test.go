@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pandafw/pango/log"
 	"github.com/pandafw/pango/str"
 )
 
@@ -130,6 +131,40 @@ func TestJSONLog(t *testing.T) {
 	assertJsonResult(t, result, 404, "GET", "/notfound")
 }
 
+func TestRequestIDLog(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	router := gin.New()
+
+	writer := io.MultiWriter(buffer, os.Stdout)
+	logger := New(writer, "text:%i %m %u%n")
+	logger.SetRequestID(DefaultRequestIDHeader)
+	router.Use(logger.Handler())
+
+	rid := ""
+	router.Any("/example", func(c *gin.Context) {
+		rid, _ = log.ContextFields(c.Request.Context())[log.FieldRequestID].(string)
+	})
+
+	req := httptest.NewRequest("GET", "/example", nil)
+	req.Header.Set(DefaultRequestIDHeader, "r123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if rid != "r123" {
+		t.Errorf("requestID = %v, want %v", rid, "r123")
+	}
+	if w.Header().Get(DefaultRequestIDHeader) != "r123" {
+		t.Errorf("response header %s = %v, want %v", DefaultRequestIDHeader, w.Header().Get(DefaultRequestIDHeader), "r123")
+	}
+	assertContains(t, "GET /example", buffer.String(), "r123 GET /example")
+
+	buffer.Reset()
+	performRequest(router, "GET", "/example")
+	if len(rid) != 16 {
+		t.Errorf("requestID = %v, want random string", rid)
+	}
+	assertContains(t, "GET /example", buffer.String(), rid+" GET /example")
+}
+
 func performRequest(r http.Handler, method, path string) *httptest.ResponseRecorder {
 	req := httptest.NewRequest(method, path, nil)
 	w := httptest.NewRecorder()
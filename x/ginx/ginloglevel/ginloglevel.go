@@ -0,0 +1,209 @@
+// Package ginloglevel list the loggers with the effective levels, and change the global or the logger level at runtime.
+// Usage:
+//
+//	router.Any("/loglevel", ginloglevel.New(log.Default()).Handler())
+//
+// GET: list the global level, the configured logger levels, the loggers (created by log.GetLogger) with the effective levels,
+// and the original levels of the pending reverts.
+//
+// POST (query or form parameters):
+//
+//	logger: the logger name or wildcard pattern, "" or "*" for the global level
+//	level:  the new level (e.g. "debug"), "" or "none" to remove the logger level
+//	ttl:    revert to the previous level after the duration (e.g. "10m"), default: never revert
+package ginloglevel
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pandafw/pango/log"
+)
+
+// jsonLogger the json object of a logger
+type jsonLogger struct {
+	Name  string `json:"name"`
+	Level string `json:"level"`
+}
+
+// jsonLevels the json object of the levels (the reverts are the original levels of the pending reverts)
+type jsonLevels struct {
+	Level   string            `json:"level"`
+	Levels  map[string]string `json:"levels"`
+	Loggers []*jsonLogger     `json:"loggers"`
+	Reverts map[string]string `json:"reverts,omitempty"`
+}
+
+// LevelHandler log level handler for GIN
+type LevelHandler struct {
+	log      *log.Log
+	disabled bool
+
+	mutex   sync.Mutex
+	reverts map[string]*levelRevert
+}
+
+// levelRevert a pending revert of the logger level
+type levelRevert struct {
+	origin log.Level
+	timer  *time.Timer
+}
+
+// New create a log level handler for the log 'lg'
+func New(lg *log.Log) *LevelHandler {
+	return &LevelHandler{log: lg, reverts: make(map[string]*levelRevert)}
+}
+
+// Disable disable the log level handler or not
+func (lh *LevelHandler) Disable(disabled bool) {
+	lh.disabled = disabled
+}
+
+// Handler returns the gin.HandlerFunc
+func (lh *LevelHandler) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lh.handle(c)
+	}
+}
+
+// handle process gin request
+func (lh *LevelHandler) handle(c *gin.Context) {
+	if lh.log == nil || lh.disabled {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPost, http.MethodPut:
+		if err := lh.change(c); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+	default:
+		c.AbortWithStatus(http.StatusMethodNotAllowed)
+		return
+	}
+
+	c.JSON(http.StatusOK, lh.levels())
+}
+
+// levels get the global level, the logger levels and the loggers with the effective levels
+func (lh *LevelHandler) levels() *jsonLevels {
+	jl := &jsonLevels{
+		Level:   lh.log.GetLevel().String(),
+		Levels:  make(map[string]string),
+		Loggers: []*jsonLogger{},
+	}
+
+	for k, v := range lh.log.GetLevels() {
+		jl.Levels[k] = v.String()
+	}
+
+	for _, n := range lh.log.GetLoggerNames() {
+		jl.Loggers = append(jl.Loggers, &jsonLogger{
+			Name:  n,
+			Level: lh.log.GetLogger(n).GetLevel().String(),
+		})
+	}
+
+	lh.mutex.Lock()
+	if len(lh.reverts) > 0 {
+		jl.Reverts = make(map[string]string, len(lh.reverts))
+		for n, lr := range lh.reverts {
+			if n == "" {
+				n = "*"
+			}
+			jl.Reverts[n] = lr.origin.String()
+		}
+	}
+	lh.mutex.Unlock()
+
+	return jl
+}
+
+// change change the global or the logger level by the request parameters
+func (lh *LevelHandler) change(c *gin.Context) error {
+	name := c.Request.FormValue("logger")
+	if name == "*" {
+		name = ""
+	}
+
+	slvl := strings.ToLower(c.Request.FormValue("level"))
+	lvl := log.ParseLevel(slvl)
+	if lvl == log.LevelNone && slvl != "" && slvl != "none" {
+		return fmt.Errorf("Invalid level %q", slvl)
+	}
+	if lvl == log.LevelNone && name == "" {
+		return fmt.Errorf("Missing global level")
+	}
+
+	var ttl time.Duration
+	if s := c.Request.FormValue("ttl"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("Invalid ttl %q", s)
+		}
+		ttl = d
+	}
+
+	lh.SetLevel(name, lvl, ttl)
+	return nil
+}
+
+// SetLevel set the level of the logger 'name' ("" for the global level) to 'lvl' (LevelNone: remove the logger level).
+// If ttl > 0, the level is reverted to the previous level after ttl.
+// The pending revert of the same logger is replaced, and the original level is kept to revert.
+func (lh *LevelHandler) SetLevel(name string, lvl log.Level, ttl time.Duration) {
+	lh.mutex.Lock()
+	defer lh.mutex.Unlock()
+
+	origin := lh.getLevel(name)
+	if lr, ok := lh.reverts[name]; ok {
+		lr.timer.Stop()
+		origin = lr.origin
+		delete(lh.reverts, name)
+	}
+
+	lh.setLevel(name, lvl)
+
+	if ttl > 0 {
+		lr := &levelRevert{origin: origin}
+		lr.timer = time.AfterFunc(ttl, func() {
+			lh.revert(name, lr)
+		})
+		lh.reverts[name] = lr
+	}
+}
+
+// revert revert the level of the logger 'name' to the original level
+func (lh *LevelHandler) revert(name string, lr *levelRevert) {
+	lh.mutex.Lock()
+	defer lh.mutex.Unlock()
+
+	if lh.reverts[name] != lr {
+		return
+	}
+
+	lh.setLevel(name, lr.origin)
+	delete(lh.reverts, name)
+}
+
+func (lh *LevelHandler) getLevel(name string) log.Level {
+	if name == "" {
+		return lh.log.GetLevel()
+	}
+	return lh.log.GetLevels()[name]
+}
+
+func (lh *LevelHandler) setLevel(name string, lvl log.Level) {
+	if name == "" {
+		lh.log.SetLevel(lvl)
+		return
+	}
+	lh.log.SetLoggerLevel(name, lvl)
+}
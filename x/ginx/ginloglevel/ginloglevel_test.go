@@ -0,0 +1,144 @@
+package ginloglevel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pandafw/pango/log"
+)
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func newTestRouter() (*gin.Engine, *log.Log) {
+	lg := log.NewLog()
+	lg.SetLevel(log.LevelInfo)
+	lg.SetLevels(map[string]log.Level{"sql": log.LevelDebug})
+	lg.GetLogger("sql")
+	lg.GetLogger("web")
+
+	router := gin.New()
+	router.Any("/loglevel", New(lg).Handler())
+	return router, lg
+}
+
+func performRequest(r http.Handler, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeLevels(t *testing.T, w *httptest.ResponseRecorder) *jsonLevels {
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %q", w.Code, w.Body.String())
+	}
+
+	jl := &jsonLevels{}
+	if err := json.Unmarshal(w.Body.Bytes(), jl); err != nil {
+		t.Fatalf("invalid json %q: %v", w.Body.String(), err)
+	}
+	return jl
+}
+
+func TestList(t *testing.T) {
+	router, _ := newTestRouter()
+
+	jl := decodeLevels(t, performRequest(router, "GET", "/loglevel"))
+	if jl.Level != "INFO" {
+		t.Errorf("level = %q, want INFO", jl.Level)
+	}
+	if len(jl.Levels) != 1 || jl.Levels["sql"] != "DEBUG" {
+		t.Errorf("levels = %v", jl.Levels)
+	}
+	if len(jl.Loggers) != 2 || *jl.Loggers[0] != (jsonLogger{"sql", "DEBUG"}) || *jl.Loggers[1] != (jsonLogger{"web", "INFO"}) {
+		t.Errorf("loggers = %v", jl.Loggers)
+	}
+}
+
+func TestChange(t *testing.T) {
+	router, lg := newTestRouter()
+
+	decodeLevels(t, performRequest(router, "POST", "/loglevel?logger=web&level=debug"))
+	if lvl := lg.GetLogger("web").GetLevel(); lvl != log.LevelDebug {
+		t.Errorf("web level = %v, want DEBUG", lvl)
+	}
+
+	decodeLevels(t, performRequest(router, "POST", "/loglevel?logger=*&level=warn"))
+	if lvl := lg.GetLevel(); lvl != log.LevelWarn {
+		t.Errorf("global level = %v, want WARN", lvl)
+	}
+
+	jl := decodeLevels(t, performRequest(router, "POST", "/loglevel?logger=sql&level=none"))
+	if _, ok := jl.Levels["sql"]; ok {
+		t.Errorf("levels = %v", jl.Levels)
+	}
+	if lvl := lg.GetLogger("sql").GetLevel(); lvl != log.LevelWarn {
+		t.Errorf("sql level = %v, want WARN", lvl)
+	}
+}
+
+func TestChangeInvalid(t *testing.T) {
+	router, _ := newTestRouter()
+
+	for _, p := range []string{
+		"/loglevel?logger=web&level=xyz",
+		"/loglevel?logger=*&level=none",
+		"/loglevel?logger=web&level=debug&ttl=x",
+	} {
+		if w := performRequest(router, "POST", p); w.Code != http.StatusBadRequest {
+			t.Errorf("POST %s status = %d, want %d", p, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	if w := performRequest(router, "DELETE", "/loglevel"); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestChangeTTL(t *testing.T) {
+	router, lg := newTestRouter()
+
+	jl := decodeLevels(t, performRequest(router, "POST", "/loglevel?logger=sql&level=trace&ttl=100ms"))
+	if jl.Reverts["sql"] != "DEBUG" {
+		t.Errorf("reverts = %v", jl.Reverts)
+	}
+
+	// the original level is kept to revert
+	decodeLevels(t, performRequest(router, "POST", "/loglevel?logger=sql&level=error&ttl=200ms"))
+	decodeLevels(t, performRequest(router, "POST", "/loglevel?logger=*&level=debug&ttl=100ms"))
+	if lvl := lg.GetLogger("sql").GetLevel(); lvl != log.LevelError {
+		t.Errorf("sql level = %v, want ERROR", lvl)
+	}
+
+	time.Sleep(time.Millisecond * 150)
+	if lvl := lg.GetLevel(); lvl != log.LevelInfo {
+		t.Errorf("global level = %v, want INFO", lvl)
+	}
+	if lvl := lg.GetLogger("sql").GetLevel(); lvl != log.LevelError {
+		t.Errorf("sql level = %v, want ERROR", lvl)
+	}
+
+	time.Sleep(time.Millisecond * 100)
+	if lvl := lg.GetLogger("sql").GetLevel(); lvl != log.LevelDebug {
+		t.Errorf("sql level = %v, want DEBUG", lvl)
+	}
+
+	jl = decodeLevels(t, performRequest(router, "GET", "/loglevel"))
+	if len(jl.Reverts) != 0 {
+		t.Errorf("reverts = %v", jl.Reverts)
+	}
+
+	// a new level without ttl cancels the pending revert
+	decodeLevels(t, performRequest(router, "POST", "/loglevel?logger=web&level=trace&ttl=50ms"))
+	decodeLevels(t, performRequest(router, "POST", "/loglevel?logger=web&level=warn"))
+	time.Sleep(time.Millisecond * 100)
+	if lvl := lg.GetLogger("web").GetLevel(); lvl != log.LevelWarn {
+		t.Errorf("web level = %v, want WARN", lvl)
+	}
+}
@@ -0,0 +1,156 @@
+// Package ginlogmetrics render the metrics of the log (event counts, async queue, writer rejects and errors)
+// in the Prometheus text exposition format.
+// Usage:
+//
+//	router.GET("/metrics/log", ginlogmetrics.New(log.Default()).Handler())
+//
+// Metrics:
+//
+//	log_events_total{level}                         the count of the events by level
+//	log_logger_events_total{logger,level}           the count of the events by logger and level
+//	log_dropped_events_total                        the count of the events dropped by the async overflow policy
+//	log_queue_length                                the count of the queued events in async mode
+//	log_queue_capacity                              the capacity of the event queue in async mode
+//	log_writer_rejected_events_total{writer}        the count of the events rejected by the writer filter
+//	log_writer_errors_total{writer}                 the count of the writer errors
+//	log_writer_last_error_timestamp_seconds{writer} the time of the last writer error
+//
+// The last writer error message is not exported as a label (unbounded cardinality), see log.Metrics.Writers.
+package ginlogmetrics
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pandafw/pango/log"
+)
+
+// ContentType the content type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// levels the levels of the metrics
+var levels = []log.Level{log.LevelFatal, log.LevelError, log.LevelWarn, log.LevelInfo, log.LevelDebug, log.LevelTrace}
+
+// LogMetrics log metrics handler for GIN
+type LogMetrics struct {
+	log      *log.Log
+	disabled bool
+}
+
+// New create a log metrics handler for the log 'lg'
+func New(lg *log.Log) *LogMetrics {
+	return &LogMetrics{log: lg}
+}
+
+// Disable disable the log metrics handler or not
+func (lm *LogMetrics) Disable(disabled bool) {
+	lm.disabled = disabled
+}
+
+// Handler returns the gin.HandlerFunc
+func (lm *LogMetrics) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lm.handle(c)
+	}
+}
+
+// handle process gin request
+func (lm *LogMetrics) handle(c *gin.Context) {
+	if lm.log == nil || lm.disabled {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	bb := &bytes.Buffer{}
+	WriteMetrics(bb, lm.log.GetMetrics())
+	c.Data(http.StatusOK, ContentType, bb.Bytes())
+}
+
+// WriteMetrics write the log metrics 'm' to the buffer 'bb' in the Prometheus text exposition format
+func WriteMetrics(bb *bytes.Buffer, m *log.Metrics) {
+	writeHeader(bb, "log_events_total", "counter", "The count of the log events by level.")
+	for _, lvl := range levels {
+		writeSample(bb, "log_events_total", m.Events[lvl], "level", levelLabel(lvl))
+	}
+
+	writeHeader(bb, "log_logger_events_total", "counter", "The count of the log events by logger and level.")
+	names := make([]string, 0, len(m.LoggerEvents))
+	for n := range m.LoggerEvents {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		for _, lvl := range levels {
+			if v, ok := m.LoggerEvents[n][lvl]; ok {
+				writeSample(bb, "log_logger_events_total", v, "logger", n, "level", levelLabel(lvl))
+			}
+		}
+	}
+
+	writeHeader(bb, "log_dropped_events_total", "counter", "The count of the log events dropped by the async overflow policy.")
+	writeSample(bb, "log_dropped_events_total", m.Dropped)
+
+	writeHeader(bb, "log_queue_length", "gauge", "The count of the queued log events in async mode.")
+	writeSample(bb, "log_queue_length", m.QueueLength)
+
+	writeHeader(bb, "log_queue_capacity", "gauge", "The capacity of the log event queue in async mode.")
+	writeSample(bb, "log_queue_capacity", m.QueueCapacity)
+
+	writeHeader(bb, "log_writer_rejected_events_total", "counter", "The count of the log events rejected by the writer filter.")
+	for _, ws := range m.Writers {
+		writeSample(bb, "log_writer_rejected_events_total", ws.Rejected, "writer", ws.Name)
+	}
+
+	writeHeader(bb, "log_writer_errors_total", "counter", "The count of the log writer errors.")
+	for _, ws := range m.Writers {
+		writeSample(bb, "log_writer_errors_total", ws.Errors, "writer", ws.Name)
+	}
+
+	writeHeader(bb, "log_writer_last_error_timestamp_seconds", "gauge", "The time of the last log writer error.")
+	for _, ws := range m.Writers {
+		if ws.Errors > 0 {
+			ts := strconv.FormatFloat(float64(ws.LastErrorTime.UnixNano())/1e9, 'f', 3, 64)
+			writeSample(bb, "log_writer_last_error_timestamp_seconds", ts, "writer", ws.Name)
+		}
+	}
+}
+
+func levelLabel(lvl log.Level) string {
+	return strings.ToLower(lvl.String())
+}
+
+func writeHeader(bb *bytes.Buffer, name, typ, help string) {
+	fmt.Fprintf(bb, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(bb, "# TYPE %s %s\n", name, typ)
+}
+
+// writeSample write a sample line "name{label="value",...} value"
+func writeSample(bb *bytes.Buffer, name string, value interface{}, labels ...string) {
+	bb.WriteString(name)
+	if len(labels) > 1 {
+		bb.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				bb.WriteByte(',')
+			}
+			bb.WriteString(labels[i])
+			bb.WriteString(`="`)
+			bb.WriteString(escapeLabel(labels[i+1]))
+			bb.WriteByte('"')
+		}
+		bb.WriteByte('}')
+	}
+	fmt.Fprintf(bb, " %v\n", value)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escape the label value: backslash, double-quote and line feed
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
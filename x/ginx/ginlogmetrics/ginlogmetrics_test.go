@@ -0,0 +1,144 @@
+package ginlogmetrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pandafw/pango/log"
+)
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func performRequest(r http.Handler, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestWriteMetrics(t *testing.T) {
+	m := &log.Metrics{
+		Events: map[log.Level]uint64{log.LevelError: 2, log.LevelInfo: 5},
+		LoggerEvents: map[string]map[log.Level]uint64{
+			"web": {log.LevelInfo: 3},
+			"":    {log.LevelError: 2, log.LevelInfo: 2},
+		},
+		Dropped:       1,
+		QueueLength:   3,
+		QueueCapacity: 100,
+		Writers: []*log.WriterStats{
+			{Name: `FileWriter("a.log")`, Rejected: 4, Errors: 1, LastError: "x\"y\\z\nw", LastErrorTime: time.Unix(1600000000, 500000000)},
+			{Name: "StreamWriter", Rejected: 2},
+		},
+	}
+
+	bb := &bytes.Buffer{}
+	WriteMetrics(bb, m)
+
+	want := `# HELP log_events_total The count of the log events by level.
+# TYPE log_events_total counter
+log_events_total{level="fatal"} 0
+log_events_total{level="error"} 2
+log_events_total{level="warn"} 0
+log_events_total{level="info"} 5
+log_events_total{level="debug"} 0
+log_events_total{level="trace"} 0
+# HELP log_logger_events_total The count of the log events by logger and level.
+# TYPE log_logger_events_total counter
+log_logger_events_total{logger="",level="error"} 2
+log_logger_events_total{logger="",level="info"} 2
+log_logger_events_total{logger="web",level="info"} 3
+# HELP log_dropped_events_total The count of the log events dropped by the async overflow policy.
+# TYPE log_dropped_events_total counter
+log_dropped_events_total 1
+# HELP log_queue_length The count of the queued log events in async mode.
+# TYPE log_queue_length gauge
+log_queue_length 3
+# HELP log_queue_capacity The capacity of the log event queue in async mode.
+# TYPE log_queue_capacity gauge
+log_queue_capacity 100
+# HELP log_writer_rejected_events_total The count of the log events rejected by the writer filter.
+# TYPE log_writer_rejected_events_total counter
+log_writer_rejected_events_total{writer="FileWriter(\"a.log\")"} 4
+log_writer_rejected_events_total{writer="StreamWriter"} 2
+# HELP log_writer_errors_total The count of the log writer errors.
+# TYPE log_writer_errors_total counter
+log_writer_errors_total{writer="FileWriter(\"a.log\")"} 1
+log_writer_errors_total{writer="StreamWriter"} 0
+# HELP log_writer_last_error_timestamp_seconds The time of the last log writer error.
+# TYPE log_writer_last_error_timestamp_seconds gauge
+log_writer_last_error_timestamp_seconds{writer="FileWriter(\"a.log\")"} 1600000000.500
+`
+	if bb.String() != want {
+		t.Errorf("WriteMetrics() =\n%s\nwant:\n%s", bb.String(), want)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ginlogmetrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// a file path under a regular file can not be opened
+	file := filepath.Join(dir, "file")
+	ioutil.WriteFile(file, []byte{}, 0666)
+	path := filepath.Join(file, "error.log")
+
+	lg := log.NewLog()
+	lg.SetWriter(&log.FileWriter{Path: path})
+	lg.Info("info")
+	lg.GetLogger("sql").Error("error")
+
+	router := gin.New()
+	router.GET("/metrics", New(lg).Handler())
+
+	w := performRequest(router, "GET", "/metrics")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("content type = %q", ct)
+	}
+
+	body := w.Body.String()
+	for _, s := range []string{
+		`log_events_total{level="info"} 1`,
+		`log_events_total{level="error"} 1`,
+		`log_logger_events_total{logger="sql",level="error"} 1`,
+		`log_writer_errors_total{writer="FileWriter(` + strings.ReplaceAll(`"`+path+`"`, `"`, `\"`) + `)"}`,
+	} {
+		if !strings.Contains(body, s) {
+			t.Errorf("missing %q in\n%s", s, body)
+		}
+	}
+}
+
+func TestDisable(t *testing.T) {
+	lm := New(log.NewLog())
+	lm.Disable(true)
+
+	router := gin.New()
+	router.GET("/metrics", lm.Handler())
+
+	if w := performRequest(router, "GET", "/metrics"); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if s := escapeLabel("x\"y\\z\nw"); s != `x\"y\\z\nw` {
+		t.Errorf("escapeLabel() = %q", s)
+	}
+}
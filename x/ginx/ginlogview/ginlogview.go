@@ -0,0 +1,113 @@
+// Package ginlogview render the recent log events kept by the log.MemoryWriter as JSON or text.
+// Usage:
+//
+//	mw := log.NewMemoryWriter(1000)
+//	log.SetWriter(log.NewMultiWriter(log.GetWriter(), mw))
+//
+//	router.GET("/logs", ginlogview.New(mw).Handler())
+//
+// Query parameters:
+//
+//	level:  the minimum level (e.g. "warn"), default: all levels
+//	logger: the logger name or wildcard pattern (e.g. "web.*"), default: all loggers
+//	limit:  the max count of the last events, default: all events
+//	format: "json" (default) or "text"
+package ginlogview
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pandafw/pango/log"
+)
+
+// DefaultTimeFormat default json time format
+const DefaultTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// jsonEvent the json object of a log event
+type jsonEvent struct {
+	When   string                 `json:"when"`
+	Level  string                 `json:"level"`
+	Logger string                 `json:"logger"`
+	Msg    string                 `json:"msg"`
+	File   string                 `json:"file,omitempty"`
+	Line   int                    `json:"line,omitempty"`
+	Func   string                 `json:"func,omitempty"`
+	Trace  string                 `json:"trace,omitempty"`
+	Fields map[string]interface{} `json:"fields,omitempty"`
+	Error  *log.ErrorInfo         `json:"error,omitempty"`
+}
+
+// LogView log view handler for GIN
+type LogView struct {
+	writer    *log.MemoryWriter
+	formatter log.Formatter
+	disabled  bool
+}
+
+// New create a log view handler for the memory writer
+func New(mw *log.MemoryWriter) *LogView {
+	return &LogView{writer: mw, formatter: log.TextFmtDefault}
+}
+
+// SetFormatter set the formatter of the text format
+func (lv *LogView) SetFormatter(f log.Formatter) {
+	lv.formatter = f
+}
+
+// Disable disable the log view or not
+func (lv *LogView) Disable(disabled bool) {
+	lv.disabled = disabled
+}
+
+// Handler returns the gin.HandlerFunc
+func (lv *LogView) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lv.handle(c)
+	}
+}
+
+// handle process gin request
+func (lv *LogView) handle(c *gin.Context) {
+	if lv.writer == nil || lv.disabled {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	lvl := log.ParseLevel(c.Query("level"))
+	name := c.Query("logger")
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	les := lv.writer.Query(lvl, name, limit)
+
+	if c.Query("format") == "text" {
+		bb := &bytes.Buffer{}
+		for _, le := range les {
+			lv.formatter.Write(bb, le)
+		}
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", bb.Bytes())
+		return
+	}
+
+	jes := make([]*jsonEvent, 0, len(les))
+	for _, le := range les {
+		je := &jsonEvent{
+			When:   le.When.Format(DefaultTimeFormat),
+			Level:  le.Level.String(),
+			Msg:    le.Msg,
+			File:   le.File,
+			Line:   le.Line,
+			Func:   le.Func,
+			Trace:  le.Trace,
+			Fields: le.Fields,
+			Error:  le.Error,
+		}
+		if le.Logger != nil {
+			je.Logger = le.Logger.GetName()
+		}
+		jes = append(jes, je)
+	}
+	c.JSON(http.StatusOK, jes)
+}
@@ -0,0 +1,136 @@
+package ginlogview
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pandafw/pango/log"
+)
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func newTestRouter() (*gin.Engine, *log.MemoryWriter) {
+	mw := log.NewMemoryWriter(10)
+
+	lg := log.NewLog()
+	lg.SetWriter(mw)
+	lg.GetLogger("web").Info("hello")
+	lg.GetLogger("sql").Error("failed")
+
+	router := gin.New()
+	router.GET("/logs", New(mw).Handler())
+	return router, mw
+}
+
+func performRequest(r http.Handler, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestJSON(t *testing.T) {
+	router, _ := newTestRouter()
+
+	w := performRequest(router, "GET", "/logs")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var jes []map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &jes); err != nil {
+		t.Fatalf("invalid json %q: %v", w.Body.String(), err)
+	}
+	if len(jes) != 2 {
+		t.Fatalf("len = %d, want 2", len(jes))
+	}
+	if jes[0]["msg"] != "hello" || jes[0]["level"] != "INFO" || jes[0]["logger"] != "web" {
+		t.Errorf("jes[0] = %v", jes[0])
+	}
+	if jes[1]["msg"] != "failed" || jes[1]["level"] != "ERROR" || jes[1]["logger"] != "sql" {
+		t.Errorf("jes[1] = %v", jes[1])
+	}
+}
+
+func TestJSONQuery(t *testing.T) {
+	router, _ := newTestRouter()
+
+	cs := []struct {
+		path string
+		want []string
+	}{
+		{"/logs?level=error", []string{"failed"}},
+		{"/logs?logger=web", []string{"hello"}},
+		{"/logs?logger=w*", []string{"hello"}},
+		{"/logs?limit=1", []string{"failed"}},
+		{"/logs?level=fatal", []string{}},
+	}
+
+	for i, c := range cs {
+		w := performRequest(router, "GET", c.path)
+
+		var jes []map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &jes); err != nil {
+			t.Fatalf("[%d] invalid json %q: %v", i, w.Body.String(), err)
+		}
+
+		msgs := []string{}
+		for _, je := range jes {
+			msgs = append(msgs, je["msg"].(string))
+		}
+		if strings.Join(msgs, ",") != strings.Join(c.want, ",") {
+			t.Errorf("[%d] %s = %v, want %v", i, c.path, msgs, c.want)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	mw := log.NewMemoryWriter(10)
+
+	lg := log.NewLog()
+	lg.SetWriter(mw)
+	lg.GetLogger("web").Info("hello")
+	lg.GetLogger("sql").Error("failed")
+
+	lv := New(mw)
+	lv.SetFormatter(log.NewTextFormatter("%l %c - %m%n"))
+
+	router := gin.New()
+	router.GET("/logs", lv.Handler())
+
+	w := performRequest(router, "GET", "/logs?format=text")
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("content type = %q", ct)
+	}
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines = %q", lines)
+	}
+	if lines[0] != "INFO web - hello" {
+		t.Errorf("lines[0] = %q", lines[0])
+	}
+	if lines[1] != "ERROR sql - failed" {
+		t.Errorf("lines[1] = %q", lines[1])
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	mw := log.NewMemoryWriter(10)
+	lv := New(mw)
+	lv.Disable(true)
+
+	router := gin.New()
+	router.GET("/logs", lv.Handler())
+
+	w := performRequest(router, "GET", "/logs")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}